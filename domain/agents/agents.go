@@ -2,57 +2,195 @@ package agents
 
 import (
 	"fmt"
-	"math"
+	"runtime"
 	"time"
 	"tjweldon/archetypal-agents/domain/world"
 	"tjweldon/archetypal-agents/utils"
+	"tjweldon/archetypal-agents/worlds"
 )
 
+// Defaults for Scenarios and archetypes that are not configured otherwise
 var (
-	width    float64 = 800
-	height   float64 = 400
-	maxSpeed float64 = 10.0
+	width      float64 = 800
+	height     float64 = 400
+	maxSpeed   float64 = 10.0
+	population int     = 100
 )
 
-// Agent represents an atomic interacting component of the simulation
+// Agent represents an atomic interacting component of the simulation. What an agent wants to do is
+// described by its Behaviours, whose steering forces are summed every step.
 type Agent struct {
+	ID                 int // Unique within the State and never reused, assigned when the agent is added
 	Position, Velocity *world.Vector
-}
+	Behaviours         []WeightedBehaviour
+	MaxSpeed           float64       // Velocities are limited to this magnitude, zero means unlimited
+	Heading            float64       // The direction the agent faces, in radians anticlockwise from the x axis
+	AngularVelocity    float64       // The rate the heading turned at during the most recent step, in radians per second
+	TurnRate           float64       // The fastest the agent can turn, in radians per second, zero means unlimited
+	Random             *utils.Random // The agent's own source of randomness, for stochastic behaviours
+
+	// Archetypes, nil unless the agent embodies that archetype
+	Lover    *Lover
+	Ruler    *Ruler
+	Predator *Predator
+	Prey     *Prey
+
+	Sovereign *Agent // The Ruler this agent is a subject of, if any
 
-// NewAgent initialises an agent that starts at the top left
-func NewAgent(positions, velocities *world.MetricSpace2D, randomise bool) *Agent {
-	var position, velocity *world.Vector
-	if !randomise {
-		position = positions.ZeroVector()
-		velocity = velocities.ZeroVector()
-	} else {
-		position = positions.NewVector(utils.RandFloat(0, width), utils.RandFloat(0, height))
+	Age       float64    // Seconds since the agent was born
+	Lifecycle *Lifecycle // How the agent ages and reproduces, nil if it lives forever without offspring
+
+	Metabolism *Metabolism // The agent's energy budget, nil if it needs no energy
+	Perception *Perception // What the agent can sense of the others, nil if it senses everything
+	Body       *Body       // The agent's extent and mass, nil if it is a point that collides with nothing
+
+	Group int // ID of the cluster the agent was in when the agents were last clustered, zero if none
+}
 
-		// Use plane polar for initial randomisation since that's easier when a max magnitude is imposed
-		r, theta := utils.RandFloat(0, maxSpeed), utils.RandFloat(0, 2*math.Pi)
-		velocity = velocities.NewVector(r*math.Cos(theta), r*math.Sin(theta))
+// Archetype names the archetype the agent embodies, plain agents are "agent"
+func (a *Agent) Archetype() string {
+	switch {
+	case a.Lover != nil:
+		return "lover"
+	case a.Ruler != nil:
+		return "ruler"
+	case a.Predator != nil:
+		return "predator"
+	case a.Prey != nil:
+		return "prey"
+	default:
+		return "agent"
 	}
-	return &Agent{position, velocity}
+}
+
+// NewAgent initialises a stationary agent that starts at the top left, it is placed by a Spawn.
+// The agent's own source of randomness is split from random.
+func NewAgent(positions, velocities *world.MetricSpace2D, random *utils.Random) *Agent {
+	return &Agent{Position: positions.ZeroVector(), Velocity: velocities.ZeroVector(), Random: random.Split()}
 }
 
 // State represents a static (and informationally complete) snapshot of the simulation at a given time
 type State struct {
 	CoordinateSystem *world.MetricSpace2D
+	Velocities       *world.MetricSpace2D
 	Agents           []*Agent
+	Bonds            []*Bond
+	BondEvents       []BondEvent // Changes to the bond graph during the most recent step
+	LifeEvents       []LifeEvent // Agents added and removed during the most recent step
+	Maze             *worlds.Maze
+	Flow             *worlds.FlowField
+	Resources        *worlds.Resources
+	Collisions       *Collisions // How agents with bodies collide, nil if they pass through each other
+	Clustering       *Clustering // How agents are grouped into clusters, nil if they are not
+	CellSize         float64     // Side of the cells of the spatial index used for neighbour queries
+
+	index       *world.CellList    // Spatial index of the agents' positions, nil if out of date
+	indexed     []*world.Vector    // The positions the index was built from
+	pool        *pool              // The goroutines the agents are updated on, nil updates them in turn
+	adjacency   map[*Agent][]*Bond // The bonds of each agent
+	territories int                // The number of territories established so far, used to assign Territory IDs
+	ids         int                // The number of agents added so far, used to assign agent IDs
+	groups      int                // The number of groups found so far, used to assign group IDs
+	random      *utils.Random      // The source agents added without their own source of randomness are given one from
 }
 
-// NewState initialises a new State struct with the position and velocity vector spaces expressed as
-// a pair of world.MetricSpace2D
+// NewState initialises a new State struct with no agents, with the position and velocity vector
+// spaces expressed as a pair of world.MetricSpace2D. Agents added to it without a source of
+// randomness are given one split from a source seeded with zero.
 func NewState(positions, velocities *world.MetricSpace2D) *State {
-	agents := make([]*Agent, 100)
-	for index := range agents {
-		agents[index] = NewAgent(positions, velocities, true)
+	return &State{
+		Agents:           []*Agent{},
+		CoordinateSystem: positions,
+		Velocities:       velocities,
+		CellSize:         50,
+		random:           utils.NewRandom(0),
+	}
+}
+
+// reindex brings the spatial index up to date with the agents' positions. It must be called
+// whenever the agents have moved, until then neighbour queries scan every agent. The index is only
+// rebuilt if an agent has moved since it was built, so the index built at the end of a step
+// serves the first evaluation of the acceleration in the next.
+func (s *State) reindex() {
+	if s.index != nil && s.indexCurrent() {
+		return
 	}
-	return &State{Agents: agents, CoordinateSystem: positions}
+	cellSize := s.CellSize
+	if cellSize <= 0 {
+		cellSize = 50
+	}
+	s.indexed = s.positionsOf()
+	s.index = world.NewCellList(s.CoordinateSystem, cellSize, s.indexed)
+}
+
+// indexCurrent reports whether every agent is where it was when the spatial index was built
+func (s *State) indexCurrent() bool {
+	if len(s.indexed) != len(s.Agents) {
+		return false
+	}
+	for index, agent := range s.Agents {
+		if *agent.Position != *s.indexed[index] {
+			return false
+		}
+	}
+	return true
+}
+
+// WithinRadius returns the indices into State.Agents of the agents strictly within radius of point
+func (s State) WithinRadius(point *world.Vector, radius float64) []int {
+	if s.index != nil {
+		return s.index.WithinRadius(point, radius)
+	}
+	found := make([]int, 0)
+	for index, agent := range s.Agents {
+		if s.CoordinateSystem.Metric(point, agent.Position) < radius {
+			found = append(found, index)
+		}
+	}
+	return found
+}
+
+// Neighbours returns the indices into State.Agents of the other agents strictly within radius of
+// State.Agents[agentIndex]
+func (s State) Neighbours(agentIndex int, radius float64) []int {
+	if s.index != nil {
+		return s.index.Neighbours(agentIndex, radius)
+	}
+	found := s.WithinRadius(s.Agents[agentIndex].Position, radius)
+	neighbours := found[:0]
+	for _, other := range found {
+		if other != agentIndex {
+			neighbours = append(neighbours, other)
+		}
+	}
+	return neighbours
+}
+
+// Clone returns a copy of the State whose agents can be moved without affecting the original. The
+// bond graph of the copy joins the copied agents, and the copy has its own source of randomness.
+func (s State) Clone() *State {
+	agents := make([]*Agent, s.Population())
+	clones := make(map[*Agent]*Agent, s.Population())
+	for index, agent := range s.Agents {
+		clone := *agent
+		clone.Position, clone.Velocity = agent.Position.Copy(), agent.Velocity.Copy()
+		agents[index], clones[agent] = &clone, &clone
+	}
+	bonds := s.Bonds
+	s.Agents, s.Bonds, s.BondEvents, s.LifeEvents, s.adjacency, s.index, s.indexed = agents, nil, nil, nil, nil, nil, nil
+	if s.random != nil {
+		s.random = s.random.Copy()
+	}
+	for _, bond := range bonds {
+		s.FormBond(clones[bond.A], clones[bond.B], bond.BondSpec)
+	}
+	s.BondEvents = nil
+	return &s
 }
 
 // Distances calculates an array where the value at distances[i][j] is the distance from State.Agents[i] to State.Agents[j]. This has the property that
-// distances[i][j] == distances[j][i] and distances[i][i] == 0
+// distances[i][j] == distances[j][i] and distances[i][i] == 0. This is O(N²), prefer Neighbours for large populations.
+// It takes no account of what the agents perceive, behaviours should use View.Neighbours.
 func (s State) Distances() (distances [][]float64) {
 	distances = make([][]float64, s.Population())
 	for index := range distances {
@@ -78,15 +216,203 @@ func (s State) Population() int {
 // Scenario is a complete encapsulation of the simulation. It contains the current time, state, topology and simulation timeStep
 type Scenario struct {
 	Time, DeltaT          time.Duration
+	Seed                  int64 // Every random choice of the Scenario follows from its Seed
 	positions, velocities *world.MetricSpace2D
+	bounds                Bounds // The region agents are spawned in and layers cover
+	population            int    // The number of plain agents spawned uniformly by default
 	state                 *State
+	integrator            Integrator
+	workers               int
+	random                *utils.Random
+	setup                 []Option // Options that populate the State, deferred until it exists
+
+	checkpointInterval time.Duration // Simulated time between checkpoints, zero disables them
+	checkpointLimit    int           // The most checkpoints that are kept
+	nextCheckpoint     time.Duration
+	checkpoints        []*Snapshot // In order of time
+	replay             *Scenario   // Restored from a checkpoint to reach a time in the past
 }
 
-// InitialiseScenario sets up the simulation scenario.
-func InitialiseScenario(timeStep time.Duration) Scenario {
-	toroid, euclideanPlane := world.NewEuclideanToroid(width, height), world.NewEuclideanPlane()
-	state := NewState(toroid, euclideanPlane)
-	return Scenario{positions: toroid, velocities: euclideanPlane, state: state, DeltaT: timeStep}
+// Option configures a Scenario at the point it is initialised
+type Option func(s *Scenario)
+
+// populate defers an Option until the Scenario's State has been created from its Seed, so that
+// options which add to the State may be given in any order relative to WithSeed
+func populate(option Option) Option {
+	return func(s *Scenario) {
+		s.setup = append(s.setup, option)
+	}
+}
+
+// WithSeed seeds the Scenario's source of randomness, the default is the time it is initialised.
+// Scenarios with the same Seed and Options follow identical trajectories.
+func WithSeed(seed int64) Option {
+	return func(s *Scenario) {
+		s.Seed = seed
+	}
+}
+
+// WithIntegrator selects the numerical scheme used to advance the Scenario, the default is
+// SemiImplicitEuler
+func WithIntegrator(integrator Integrator) Option {
+	return func(s *Scenario) {
+		s.integrator = integrator
+	}
+}
+
+// WithWorkers sets the number of goroutines the steering of the agents is computed on and their
+// positions and velocities are integrated on, the default is runtime.GOMAXPROCS. The goroutines
+// are started with the Scenario and reused every step. The result of a step does not depend on the
+// number of workers.
+func WithWorkers(workers int) Option {
+	return func(s *Scenario) {
+		s.workers = workers
+	}
+}
+
+// WithWorld sets the size of the world and its position space, which is usually a toroid of the
+// same size. The default is an 800 x 400 toroid.
+func WithWorld(width, height float64, positions *world.MetricSpace2D) Option {
+	return func(s *Scenario) {
+		s.bounds = Bounds{Width: width, Height: height}
+		s.positions = positions
+	}
+}
+
+// DefaultPopulation returns the number of plain agents spawned uniformly over the world unless
+// WithPopulation is given
+func DefaultPopulation() int {
+	return population
+}
+
+// WithPopulation sets the number of plain agents that are spawned uniformly over the world before
+// any other agents are added, the default is DefaultPopulation
+func WithPopulation(count int) Option {
+	return func(s *Scenario) {
+		s.population = count
+	}
+}
+
+// WithAgents adds a group of count plain agents to the Scenario, placed by spawns in turn
+func WithAgents(count int, spawns ...Spawn) Option {
+	return populate(func(s *Scenario) {
+		s.addAgents(count, spawns)
+	})
+}
+
+// addAgents adds a group of count plain agents, placed by spawns in turn
+func (s *Scenario) addAgents(count int, spawns []Spawn) {
+	group := make([]*Agent, count)
+	for index := range group {
+		group[index] = NewAgent(s.positions, s.velocities, s.random)
+	}
+	s.spawn(group, spawns)
+}
+
+// WithFlocking adds the boids rules parameterised by flocking to the behaviours of every plain
+// agent, agents that embody an archetype follow their own behaviours
+func WithFlocking(flocking Flocking) Option {
+	return populate(func(s *Scenario) {
+		for _, agent := range s.state.Agents {
+			if agent.Archetype() != "agent" {
+				continue
+			}
+			agent.Behaviours = append(agent.Behaviours, flocking.Behaviours()...)
+			agent.MaxSpeed = flocking.MaxSpeed
+		}
+	})
+}
+
+// InitialiseScenario sets up the simulation scenario. The timeStep must be positive, a Scenario
+// that did not advance as it stepped would never reach a later frame.
+func InitialiseScenario(timeStep time.Duration, options ...Option) *Scenario {
+	if timeStep <= 0 {
+		panic(fmt.Sprintf("the time step of a Scenario must be positive, not %v", timeStep))
+	}
+	scenario := &Scenario{
+		positions:  world.NewEuclideanToroid(width, height),
+		velocities: world.NewEuclideanPlane(),
+		bounds:     Bounds{Width: width, Height: height},
+		population: population,
+		DeltaT:     timeStep,
+		Seed:       time.Now().UnixNano(),
+		integrator: SemiImplicitEuler{},
+		workers:    runtime.GOMAXPROCS(0),
+	}
+	for _, option := range options {
+		option(scenario)
+	}
+	scenario.random = utils.NewRandom(scenario.Seed)
+	scenario.state = NewState(scenario.positions, scenario.velocities)
+	scenario.state.random = scenario.random
+	scenario.state.pool = newPool(scenario.workers)
+	scenario.addAgents(scenario.population, nil)
+	for _, option := range scenario.setup {
+		option(scenario)
+	}
+	scenario.setup, scenario.state.LifeEvents = nil, nil
+	scenario.cluster()
+	return scenario
+}
+
+// Step advances the simulation by a single timestep using the Scenario's Integrator. Positions
+// are summed in the position space so that agents wrap at the boundaries of a periodic topology.
+func (s *Scenario) Step() {
+	if s.checkpointInterval > 0 && s.Time >= s.nextCheckpoint {
+		s.checkpoint()
+	}
+	s.state.BondEvents, s.state.LifeEvents = nil, nil
+	previous := s.state.positionsOf()
+	s.integrator.Integrate(s.state, s.DeltaT.Seconds(), s.acceleration)
+	s.state.advect(s.Time.Seconds(), s.DeltaT.Seconds())
+	s.state.blockByWalls(previous)
+	s.state.collide()
+	s.state.reindex()
+	for _, agent := range s.state.Agents {
+		if agent.MaxSpeed > 0 {
+			agent.Velocity.Limit(agent.MaxSpeed)
+		}
+	}
+	s.state.turn(s.DeltaT.Seconds())
+	s.state.updateBonds()
+	s.state.updateLovers(s.DeltaT.Seconds())
+	s.state.updateRulers(s.DeltaT.Seconds())
+	s.state.updatePredators()
+	s.state.updateMetabolisms(s.DeltaT.Seconds())
+	s.state.updateLifecycles(s.DeltaT.Seconds())
+	s.Time += s.DeltaT
+	s.cluster()
+}
+
+// State returns the State of the Scenario, which is updated in place as the Scenario steps
+func (s *Scenario) State() *State {
+	return s.state
+}
+
+// BondEvents returns the changes to the bond graph during the most recent step
+func (s *Scenario) BondEvents() []BondEvent {
+	return s.state.BondEvents
+}
+
+// LifeEvents returns the agents added and removed during the most recent step
+func (s *Scenario) LifeEvents() []LifeEvent {
+	return s.state.LifeEvents
+}
+
+// acceleration is the Acceleration of the Scenario, the steering force of each agent's behaviours
+// plus the spring forces of its bonds, limited so that no agent turns faster than its TurnRate
+// over the step. This is the read phase of a step: the agents are spread over the workers, which
+// only read the State and each write the accelerations of their own agents, so no agent sees a
+// partially updated neighbour.
+func (s *Scenario) acceleration(state *State) []world.Vector {
+	state.reindex()
+	accelerations := make([]world.Vector, state.Population())
+	state.ForEach(func(index int) {
+		steering, springs := state.Agents[index].Steer(state.NewView(index)), state.springForce(index)
+		accelerations[index] = *steering.Accumulate(&steering, &springs)
+		state.Agents[index].limitTurn(&accelerations[index], s.DeltaT.Seconds())
+	})
+	return accelerations
 }
 
 // LPFloat serialises as a number represented to a fixed number
@@ -104,19 +430,85 @@ func (l LPFloat) MarshalJSON() ([]byte, error) {
 
 // Coords are a part of the socket API, probably shouldn't be defined here
 type Coords struct {
-	X LPFloat `json:"x"`
-	Y LPFloat `json:"y"`
+	ID        int      `json:"id"` // Identifies the agent across frames as others appear and disappear
+	X         LPFloat  `json:"x"`
+	Y         LPFloat  `json:"y"`
+	Heading   LPFloat  `json:"heading"` // Radians anticlockwise from the x axis
+	Archetype string   `json:"archetype,omitempty"`
+	Territory int      `json:"territory,omitempty"` // ID of the Territory the agent is in
+	Realm     *Realm   `json:"realm,omitempty"`     // The Territory of a Ruler
+	Energy    *LPFloat `json:"energy,omitempty"`    // The energy of an agent with a Metabolism
+	Radius    *LPFloat `json:"radius,omitempty"`    // The radius of an agent with a Body
+	Group     int      `json:"group,omitempty"`     // ID of the cluster the agent is in
+}
+
+// Realm describes a Territory to the socket client (see comment on Coords)
+type Realm struct {
+	ID     int     `json:"id"`
+	X      LPFloat `json:"x"`
+	Y      LPFloat `json:"y"`
+	Radius LPFloat `json:"radius"`
 }
 
 // Frame (see comment on Coords)
 type Frame []Coords
 
-// GetFrameAt Retrieves the frame data at time t (in seconds)
-func GetFrameAt(t float64) Frame {
-	return Frame{}
+// Frame renders the State as a Frame containing one Coords per agent, in the same order as
+// State.Agents. Positions are wrapped onto their canonical representative.
+func (s State) Frame() Frame {
+	frame := make(Frame, s.Population())
+	rulers := s.rulers()
+	for index, agent := range s.Agents {
+		x, y := agent.Position.Wrapped()
+		frame[index] = Coords{
+			ID:      agent.ID,
+			X:       LPFloat{Value: x, Digits: 2},
+			Y:       LPFloat{Value: y, Digits: 2},
+			Heading: LPFloat{Value: agent.Heading, Digits: 3},
+		}
+		if archetype := agent.Archetype(); archetype != "agent" {
+			frame[index].Archetype = archetype
+		}
+		frame[index].Territory = s.territoryOf(rulers, agent.Position)
+		frame[index].Group = agent.Group
+		if agent.Metabolism != nil {
+			frame[index].Energy = &LPFloat{Value: agent.Metabolism.Energy, Digits: 2}
+		}
+		if agent.Body != nil {
+			frame[index].Radius = &LPFloat{Value: agent.Body.Radius, Digits: 2}
+		}
+		if agent.Ruler != nil {
+			territory := agent.Ruler.Territory
+			centreX, centreY := territory.Centre.Wrapped()
+			frame[index].Realm = &Realm{
+				ID:     territory.ID,
+				X:      LPFloat{Value: centreX, Digits: 2},
+				Y:      LPFloat{Value: centreY, Digits: 2},
+				Radius: LPFloat{Value: territory.Radius, Digits: 2},
+			}
+		}
+	}
+	return frame
+}
+
+// GetFrameAt Retrieves the frame data at time t (in seconds), that is, the frame of the first step
+// at or after t. Later times are reached by stepping the simulation forward. Earlier times are
+// replayed from the nearest earlier checkpoint without disturbing the simulation, if there is no
+// such checkpoint, as there is not unless WithCheckpoints is given, they yield the current frame.
+func (s *Scenario) GetFrameAt(t float64) Frame {
+	target := time.Duration(t * float64(time.Second))
+	if target > s.Time-s.DeltaT {
+		for s.Time < target {
+			s.Step()
+		}
+		return s.state.Frame()
+	}
+	return s.replayTo(target).state.Frame()
 }
 
-// GetNextFrame is a generator function for Frame instances
-func (s Scenario) GetNextFrame() (frame Frame) {
-	return Frame{}
+// GetNextFrame is a generator function for Frame instances. Every call advances the
+// simulation by one timestep.
+func (s *Scenario) GetNextFrame() (frame Frame) {
+	s.Step()
+	return s.state.Frame()
 }
@@ -1 +1,128 @@
 package agents
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+const MaxPrecision = 1.0e-9
+
+func TestScenario_GetNextFrame_AdvancesTime(t *testing.T) {
+	scenario := InitialiseScenario(time.Second / 60)
+
+	for i := 1; i <= 10; i++ {
+		frame := scenario.GetNextFrame()
+		assert.Len(t, frame, scenario.state.Population())
+		assert.Equal(t, time.Duration(i)*scenario.DeltaT, scenario.Time)
+	}
+}
+
+func TestScenario_Step_IntegratesVelocity(t *testing.T) {
+	scenario := InitialiseScenario(time.Second / 10)
+	agent := scenario.state.Agents[0]
+	agent.Position.X, agent.Position.Y = 100, 100
+	agent.Velocity.X, agent.Velocity.Y = 10, -5
+
+	scenario.Step()
+
+	x, y := agent.Position.Wrapped()
+	assert.InDelta(t, 101.0, x, MaxPrecision)
+	assert.InDelta(t, 99.5, y, MaxPrecision)
+}
+
+func TestScenario_Step_WrapsAtBoundary(t *testing.T) {
+	scenario := InitialiseScenario(time.Second)
+	agent := scenario.state.Agents[0]
+	agent.Position.X, agent.Position.Y = width-1, 1
+	agent.Velocity.X, agent.Velocity.Y = 3, -3
+
+	scenario.Step()
+
+	x, y := agent.Position.Wrapped()
+	assert.InDelta(t, 2.0, x, MaxPrecision)
+	assert.InDelta(t, height-2, y, MaxPrecision)
+}
+
+func TestScenario_GetFrameAt_StepsForward(t *testing.T) {
+	scenario := InitialiseScenario(time.Second / 4)
+
+	scenario.GetFrameAt(2)
+
+	assert.Equal(t, 2*time.Second, scenario.Time)
+	assert.False(t, math.IsNaN(scenario.state.Agents[0].Position.X))
+}
+
+// seeded initialises a Scenario that exercises every random choice, from the given seed
+func seeded(seed int64) *Scenario {
+	return InitialiseScenario(
+		time.Second/60,
+		WithFlocking(DefaultFlocking()),
+		WithLovers(10, DefaultLover()),
+		WithRulers(3, DefaultRuler()),
+		WithMaze(8, 4),
+		WithTurbulence(6, 5),
+		WithSeed(seed),
+	)
+}
+
+func TestScenario_WithSeed_IsReproducible(t *testing.T) {
+	first, second, other := seeded(42), seeded(42), seeded(43)
+
+	assert.Equal(t, first.Walls(), second.Walls())
+	assert.NotEqual(t, first.Walls(), other.Walls())
+	for range [100]any{} {
+		assert.Equal(t, first.GetNextFrame(), second.GetNextFrame())
+	}
+	assert.NotEqual(t, first.GetNextFrame(), other.GetNextFrame())
+}
+
+// crowd initialises a flocking scenario with the given population and perception radius
+func crowd(population int, radius float64) *Scenario {
+	flocking := DefaultFlocking()
+	flocking.PerceptionRadius = radius
+	scenario := InitialiseScenario(
+		time.Second/60,
+		WithPopulation(population),
+		WithFlocking(flocking),
+	)
+	scenario.state.CellSize = radius
+	return scenario
+}
+
+func TestState_Neighbours_IndexMatchesScan(t *testing.T) {
+	state := crowd(500, 30).state
+
+	scanned := make([][]int, state.Population())
+	for index := range state.Agents {
+		scanned[index] = append([]int{}, state.Neighbours(index, 30)...)
+	}
+	state.reindex()
+
+	for index := range state.Agents {
+		assert.Equal(t, scanned[index], state.Neighbours(index, 30))
+	}
+}
+
+func TestState_reindex_OnlyAfterAgentsMove(t *testing.T) {
+	scenario := crowd(50, 30)
+	scenario.Step()
+	index := scenario.state.index
+
+	scenario.state.reindex()
+	assert.Same(t, index, scenario.state.index, "the index of a step serves the next")
+
+	scenario.state.Agents[7].Position.X += 1
+	scenario.state.reindex()
+	assert.NotSame(t, index, scenario.state.index)
+}
+
+// BenchmarkScenario_Step_10k flocks 10k agents at a density of around ten neighbours per agent
+func BenchmarkScenario_Step_10k(b *testing.B) {
+	scenario := crowd(10000, 10)
+
+	for i := 0; i < b.N; i++ {
+		scenario.Step()
+	}
+}
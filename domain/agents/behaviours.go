@@ -0,0 +1,177 @@
+package agents
+
+import (
+	"math"
+	"tjweldon/archetypal-agents/domain/world"
+	"tjweldon/archetypal-agents/worlds"
+)
+
+// Behaviour is a single drive of an agent, e.g. seeking a point or keeping away from neighbours.
+// Steer returns the steering force (an acceleration in the velocity space) the behaviour wants
+// to apply to self. Implementations must treat both self and the view as read-only.
+type Behaviour interface {
+	Steer(self *Agent, view View) world.Vector
+}
+
+// WeightedBehaviour is a Behaviour together with the factor its steering force is scaled by when
+// an agent sums its behaviours
+type WeightedBehaviour struct {
+	Behaviour Behaviour
+	Weight    float64
+}
+
+// Neighbour is another agent as seen from a given agent. The Offset is the geodesic displacement
+// to the neighbour so it is correct across the seam of a periodic topology.
+type Neighbour struct {
+	Agent    *Agent
+	Offset   world.Vector
+	Distance float64
+}
+
+// View is a read-only view of the State from the point of view of one of its agents
+type View struct {
+	state      *State
+	index      int
+	neighbours map[float64][]Neighbour // Neighbour queries already made through this View
+}
+
+// NewView returns the View of the State from State.Agents[index]
+func (s *State) NewView(index int) View {
+	return View{state: s, index: index, neighbours: make(map[float64][]Neighbour)}
+}
+
+// Space returns the position space of the State
+func (v View) Space() *world.MetricSpace2D {
+	return v.state.CoordinateSystem
+}
+
+// Velocities returns the velocity space of the State, steering forces belong to this space
+func (v View) Velocities() *world.MetricSpace2D {
+	return v.state.Velocities
+}
+
+// Offset returns the geodesic displacement from the viewing agent to a point in the position space
+func (v View) Offset(position *world.Vector) world.Vector {
+	self := v.state.Agents[v.index].Position
+	deltaX, deltaY := v.Space().GeodesicDiff(self.X, position.X, self.Y, position.Y)
+	return *v.Velocities().NewVector(deltaX, deltaY)
+}
+
+// Patches returns the resource patches of the State the viewing agent perceives, that is those it
+// is in or whose centre it perceives
+func (v View) Patches() []worlds.Patch {
+	if v.state.Resources == nil {
+		return nil
+	}
+	perceived := make([]worlds.Patch, 0, len(v.state.Resources.Patches))
+	for _, patch := range v.state.Resources.Patches {
+		offset := v.Offset(v.Space().NewVector(patch.X, patch.Y))
+		if math.Hypot(offset.X, offset.Y) < patch.Radius || v.perceives(offset) {
+			perceived = append(perceived, patch)
+		}
+	}
+	return perceived
+}
+
+// Neighbours finds the other agents strictly within radius of the viewing agent that it perceives
+// (see Perception). Behaviours of the same agent usually share a radius, so the result is
+// remembered for the lifetime of the View.
+func (v View) Neighbours(radius float64) []Neighbour {
+	if found, ok := v.neighbours[radius]; ok {
+		return found
+	}
+	reach := radius
+	if perception := v.state.Agents[v.index].Perception; perception != nil && perception.Range > 0 {
+		reach = math.Min(reach, perception.Range)
+	}
+	indices := v.state.Neighbours(v.index, reach)
+	found := make([]Neighbour, 0, len(indices))
+	for _, other := range indices {
+		agent := v.state.Agents[other]
+		offset := v.Offset(agent.Position)
+		if !v.perceives(offset) {
+			continue
+		}
+		distance := math.Sqrt(offset.X*offset.X + offset.Y*offset.Y)
+		found = append(found, Neighbour{Agent: agent, Offset: offset, Distance: distance})
+	}
+	if v.neighbours != nil {
+		v.neighbours[radius] = found
+	}
+	return found
+}
+
+// Steer is the weighted sum of the steering forces of all the agent's behaviours, dormant agents
+// do not steer
+func (a *Agent) Steer(view View) world.Vector {
+	total := view.Velocities().ZeroVector()
+	if a.Dormant() {
+		return *total
+	}
+	for _, weighted := range a.Behaviours {
+		contribution := weighted.Behaviour.Steer(a, view)
+		contribution.Scale(weighted.Weight)
+		total.Accumulate(total, &contribution)
+	}
+	return *total
+}
+
+// Limits bounds a steering force in the manner of Reynolds: the agent wants to travel in some
+// direction at MaxSpeed, and steers towards that velocity with at most MaxForce.
+type Limits struct {
+	MaxSpeed, MaxForce float64
+}
+
+// steer turns a desired direction into a steering force using Reynolds' rule:
+// steering = desired velocity at full speed - current velocity, limited to the max force
+func (l Limits) steer(self *Agent, desired world.Vector) world.Vector {
+	desired.SetMag(l.MaxSpeed)
+	braking := self.Velocity.Times(-1)
+	desired.Accumulate(&desired, &braking).Limit(l.MaxForce)
+	return desired
+}
+
+// Seek steers towards a fixed point in the position space
+type Seek struct {
+	Limits
+	Target *world.Vector
+}
+
+// Steer implements Behaviour
+func (s Seek) Steer(self *Agent, view View) world.Vector {
+	return s.steer(self, view.Offset(s.Target))
+}
+
+// Flee steers directly away from a fixed point in the position space whenever the agent is
+// within Radius of it. A zero Radius flees from any distance.
+type Flee struct {
+	Limits
+	Target *world.Vector
+	Radius float64
+}
+
+// Steer implements Behaviour
+func (f Flee) Steer(self *Agent, view View) world.Vector {
+	offset := view.Offset(f.Target)
+	if f.Radius > 0 && offset.Mag() >= f.Radius {
+		return *view.Velocities().ZeroVector()
+	}
+	return f.steer(self, offset.Times(-1))
+}
+
+// Wander steers in a direction that is the agent's current heading perturbed by a random
+// angle of at most Jitter radians either way. Stationary agents set off in a random direction.
+// The angle is drawn from the agent's own source of randomness.
+type Wander struct {
+	Limits
+	Jitter float64
+}
+
+// Steer implements Behaviour
+func (w Wander) Steer(self *Agent, view View) world.Vector {
+	heading := self.Random.Float(0, 2*math.Pi)
+	if self.Velocity.Mag() > 0 {
+		heading = math.Atan2(self.Velocity.Y, self.Velocity.X) + self.Random.Float(-w.Jitter, w.Jitter)
+	}
+	return w.steer(self, *view.Velocities().NewVector(math.Cos(heading), math.Sin(heading)))
+}
@@ -0,0 +1,75 @@
+package agents
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+	"tjweldon/archetypal-agents/domain/world"
+)
+
+// constant is a Behaviour that always steers the same way
+type constant struct {
+	x, y float64
+}
+
+func (c constant) Steer(self *Agent, view View) world.Vector {
+	return *view.Velocities().NewVector(c.x, c.y)
+}
+
+func TestAgent_Steer_SumsWeightedBehaviours(t *testing.T) {
+	state := pair()
+	agent := state.Agents[0]
+	agent.Behaviours = []WeightedBehaviour{
+		{Behaviour: constant{1, 0}, Weight: 2},
+		{Behaviour: constant{0, 1}, Weight: -3},
+	}
+
+	steering := agent.Steer(state.NewView(0))
+
+	assert.InDelta(t, 2.0, steering.X, MaxPrecision)
+	assert.InDelta(t, -3.0, steering.Y, MaxPrecision)
+}
+
+func TestSeek_AcrossSeam(t *testing.T) {
+	state := pair()
+	seek := Seek{Limits{MaxSpeed: 1, MaxForce: 10}, state.CoordinateSystem.NewVector(width-10, 200)}
+
+	steering := seek.Steer(state.Agents[0], state.NewView(0))
+
+	assert.InDelta(t, -1.0, steering.X, MaxPrecision)
+	assert.InDelta(t, 0.0, steering.Y, MaxPrecision)
+}
+
+func TestFlee_OnlyWithinRadius(t *testing.T) {
+	state := pair()
+	target := state.CoordinateSystem.NewVector(12, 200)
+
+	near := Flee{Limits{MaxSpeed: 1, MaxForce: 10}, target, 20}.Steer(state.Agents[0], state.NewView(0))
+	far := Flee{Limits{MaxSpeed: 1, MaxForce: 10}, target, 5}.Steer(state.Agents[0], state.NewView(0))
+
+	assert.InDelta(t, -1.0, near.X, MaxPrecision)
+	assert.Equal(t, 0.0, far.Mag())
+}
+
+func TestWander_StationaryAgentSetsOff(t *testing.T) {
+	state := pair()
+	wander := Wander{Limits{MaxSpeed: 5, MaxForce: 1}, 0.5}
+
+	steering := wander.Steer(state.Agents[0], state.NewView(0))
+
+	assert.InDelta(t, 1.0, steering.Mag(), MaxPrecision)
+}
+
+func TestScenario_Step_LimitsSpeed(t *testing.T) {
+	scenario := InitialiseScenario(time.Second)
+	for _, agent := range scenario.state.Agents {
+		agent.Behaviours = []WeightedBehaviour{{Behaviour: constant{100, 0}, Weight: 1}}
+		agent.MaxSpeed = 3
+	}
+
+	scenario.Step()
+
+	for _, agent := range scenario.state.Agents {
+		assert.LessOrEqual(t, agent.Velocity.Mag(), 3+MaxPrecision)
+	}
+}
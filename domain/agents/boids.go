@@ -0,0 +1,118 @@
+package agents
+
+import "tjweldon/archetypal-agents/domain/world"
+
+// Flocking parameterises Reynolds' boids steering rules: separation, cohesion and alignment.
+// Forces and speeds are expressed per second rather than per frame.
+type Flocking struct {
+	PerceptionRadius float64 // Neighbours further away than this are ignored
+	MaxSpeed         float64 // The speed each rule steers towards, velocities are limited to it
+	MaxForce         float64 // The largest steering force any one rule can apply
+
+	// Weights of each of the rules in the total steering force
+	Separation, Cohesion, Alignment float64
+}
+
+// DefaultFlocking returns a Flocking with every rule weighted equally
+func DefaultFlocking() Flocking {
+	return Flocking{
+		PerceptionRadius: 50,
+		MaxSpeed:         maxSpeed,
+		MaxForce:         maxSpeed / 2,
+		Separation:       1,
+		Cohesion:         1,
+		Alignment:        1,
+	}
+}
+
+// Behaviours returns the three boids rules as weighted behaviours
+func (f Flocking) Behaviours() []WeightedBehaviour {
+	limits := Limits{MaxSpeed: f.MaxSpeed, MaxForce: f.MaxForce}
+	return []WeightedBehaviour{
+		{Behaviour: Separation{limits, f.PerceptionRadius}, Weight: f.Separation},
+		{Behaviour: Cohesion{limits, f.PerceptionRadius, false}, Weight: f.Cohesion},
+		{Behaviour: Alignment{limits, f.PerceptionRadius, false}, Weight: f.Alignment},
+	}
+}
+
+// Separation steers away from neighbours within Radius, weighting each by the inverse of its
+// distance
+type Separation struct {
+	Limits
+	Radius float64
+}
+
+// Steer implements Behaviour
+func (s Separation) Steer(self *Agent, view View) world.Vector {
+	desired := view.Velocities().ZeroVector()
+	total := 0
+	for _, other := range view.Neighbours(s.Radius) {
+		if other.Distance == 0 {
+			continue
+		}
+		away := other.Offset.Times(-1 / (other.Distance * other.Distance))
+		desired.Accumulate(desired, &away)
+		total++
+	}
+	if total == 0 {
+		return *desired
+	}
+	return s.steer(self, *desired)
+}
+
+// Cohesion steers towards the centre of mass of the neighbours within Radius. The centre is
+// taken relative to the agent so that it lies between neighbours on either side of a seam.
+type Cohesion struct {
+	Limits
+	Radius float64
+	Kin    bool // Only neighbours of the agent's own archetype are considered
+}
+
+// Steer implements Behaviour
+func (c Cohesion) Steer(self *Agent, view View) world.Vector {
+	desired := view.Velocities().ZeroVector()
+	neighbours := kin(self, view.Neighbours(c.Radius), c.Kin)
+	if len(neighbours) == 0 {
+		return *desired
+	}
+	for _, other := range neighbours {
+		offset := other.Offset
+		desired.Accumulate(desired, &offset)
+	}
+	return c.steer(self, *desired)
+}
+
+// Alignment steers towards the mean velocity of the neighbours within Radius
+type Alignment struct {
+	Limits
+	Radius float64
+	Kin    bool // Only neighbours of the agent's own archetype are considered
+}
+
+// Steer implements Behaviour
+func (a Alignment) Steer(self *Agent, view View) world.Vector {
+	desired := view.Velocities().ZeroVector()
+	neighbours := kin(self, view.Neighbours(a.Radius), a.Kin)
+	if len(neighbours) == 0 {
+		return *desired
+	}
+	for _, other := range neighbours {
+		desired.Accumulate(desired, other.Agent.Velocity)
+	}
+	return a.steer(self, *desired)
+}
+
+// kin returns the neighbours of the same archetype as self if only is set, otherwise all of them
+func kin(self *Agent, neighbours []Neighbour, only bool) []Neighbour {
+	if !only {
+		return neighbours
+	}
+	archetype := self.Archetype()
+	found := make([]Neighbour, 0, len(neighbours))
+	for _, other := range neighbours {
+		if other.Agent.Archetype() == archetype {
+			found = append(found, other)
+		}
+	}
+	return found
+}
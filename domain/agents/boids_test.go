@@ -0,0 +1,75 @@
+package agents
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"tjweldon/archetypal-agents/domain/world"
+)
+
+// pair places two agents either side of the vertical seam of an 800x400 toroid
+func pair() *State {
+	toroid, plane := world.NewEuclideanToroid(width, height), world.NewEuclideanPlane()
+	state := NewState(toroid, plane)
+	state.Add(&Agent{Position: toroid.NewVector(2, 200), Velocity: plane.NewVector(0, 0)})
+	state.Add(&Agent{Position: toroid.NewVector(width-2, 200), Velocity: plane.NewVector(0, 5)})
+	return state
+}
+
+func TestView_Neighbours_AcrossSeam(t *testing.T) {
+	state := pair()
+
+	found := state.NewView(0).Neighbours(10)
+
+	assert.Len(t, found, 1)
+	assert.InDelta(t, 4.0, found[0].Distance, MaxPrecision)
+	assert.InDelta(t, -4.0, found[0].Offset.X, MaxPrecision)
+	assert.InDelta(t, 0.0, found[0].Offset.Y, MaxPrecision)
+}
+
+func TestCohesion_AcrossSeam(t *testing.T) {
+	state := pair()
+	cohesion := Cohesion{Limits{MaxSpeed: 10, MaxForce: 100}, 10, false}
+
+	steering := cohesion.Steer(state.Agents[0], state.NewView(0))
+
+	assert.Less(t, steering.X, 0.0, "cohesion should pull across the seam, not across the world")
+}
+
+func TestSeparation_AcrossSeam(t *testing.T) {
+	state := pair()
+	separation := Separation{Limits{MaxSpeed: 10, MaxForce: 100}, 10}
+
+	steering := separation.Steer(state.Agents[0], state.NewView(0))
+
+	assert.Greater(t, steering.X, 0.0, "separation should push away from the seam")
+}
+
+func TestAlignment_MatchesNeighbourVelocity(t *testing.T) {
+	state := pair()
+	alignment := Alignment{Limits{MaxSpeed: 5, MaxForce: 100}, 10, false}
+
+	steering := alignment.Steer(state.Agents[0], state.NewView(0))
+
+	assert.InDelta(t, 0.0, steering.X, MaxPrecision)
+	assert.InDelta(t, 5.0, steering.Y, MaxPrecision)
+}
+
+func TestAlignment_ForceIsLimited(t *testing.T) {
+	state := pair()
+	alignment := Alignment{Limits{MaxSpeed: 100, MaxForce: 1}, 10, false}
+
+	steering := alignment.Steer(state.Agents[0], state.NewView(0))
+
+	assert.InDelta(t, 1.0, steering.Mag(), MaxPrecision)
+}
+
+func TestFlocking_OutOfRangeNeighboursAreIgnored(t *testing.T) {
+	state := pair()
+	flocking := DefaultFlocking()
+	flocking.PerceptionRadius = 1
+	state.Agents[0].Behaviours = flocking.Behaviours()
+
+	steering := state.Agents[0].Steer(state.NewView(0))
+
+	assert.Equal(t, 0.0, steering.Mag())
+}
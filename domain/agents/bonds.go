@@ -0,0 +1,131 @@
+package agents
+
+import "tjweldon/archetypal-agents/domain/world"
+
+// BondSpec describes the mechanical properties of a Bond. The bond is a spring that pulls its
+// agents together when stretched beyond RestLength and pushes them apart when compressed.
+type BondSpec struct {
+	RestLength     float64 // Separation at which the bond exerts no force
+	Stiffness      float64 // Acceleration per unit of extension beyond RestLength
+	BreakingStrain float64 // Strain at which the bond breaks (see Bond.strain), zero never breaks
+}
+
+// Bond is an edge in the bond graph of a State, joining two agents with a spring
+type Bond struct {
+	BondSpec
+	A, B *Agent
+}
+
+// Other returns the agent at the other end of the bond from agent
+func (b *Bond) Other(agent *Agent) *Agent {
+	if b.A == agent {
+		return b.B
+	}
+	return b.A
+}
+
+// extension returns the geodesic displacement from A to B and how far the bond is stretched beyond
+// its rest length. Compressed bonds have a negative extension.
+func (b *Bond) extension(space *world.MetricSpace2D) (deltaX, deltaY, extension float64) {
+	deltaX, deltaY = space.GeodesicDiff(b.A.Position.X, b.B.Position.X, b.A.Position.Y, b.B.Position.Y)
+	return deltaX, deltaY, space.Metric(b.A.Position, b.B.Position) - b.RestLength
+}
+
+// strain is the extension of the bond as a fraction of its rest length. A bond with no rest length
+// cannot be stretched by a fraction of it, so its strain is its extension.
+func (b *Bond) strain(space *world.MetricSpace2D) float64 {
+	_, _, extension := b.extension(space)
+	if b.RestLength == 0 {
+		return extension
+	}
+	return extension / b.RestLength
+}
+
+// BondEventKind distinguishes the formation of a bond from its breakage
+type BondEventKind string
+
+const (
+	BondFormed BondEventKind = "formed"
+	BondBroken BondEventKind = "broken"
+)
+
+// BondEvent records a change to the bond graph
+type BondEvent struct {
+	Kind BondEventKind
+	Bond *Bond
+}
+
+// FormBond joins a and b with a new Bond, unless they are already bonded, and records a BondFormed
+// event. It returns the bond between the two agents.
+func (s *State) FormBond(a, b *Agent, spec BondSpec) *Bond {
+	if existing := s.BondBetween(a, b); existing != nil {
+		return existing
+	}
+	bond := &Bond{BondSpec: spec, A: a, B: b}
+	s.Bonds = append(s.Bonds, bond)
+	if s.adjacency == nil {
+		s.adjacency = make(map[*Agent][]*Bond)
+	}
+	s.adjacency[a] = append(s.adjacency[a], bond)
+	s.adjacency[b] = append(s.adjacency[b], bond)
+	s.BondEvents = append(s.BondEvents, BondEvent{Kind: BondFormed, Bond: bond})
+	return bond
+}
+
+// BreakBond removes bond from the bond graph and records a BondBroken event
+func (s *State) BreakBond(bond *Bond) {
+	s.Bonds = without(s.Bonds, bond)
+	s.adjacency[bond.A] = without(s.adjacency[bond.A], bond)
+	s.adjacency[bond.B] = without(s.adjacency[bond.B], bond)
+	s.BondEvents = append(s.BondEvents, BondEvent{Kind: BondBroken, Bond: bond})
+}
+
+// BondsOf returns the bonds of agent
+func (s State) BondsOf(agent *Agent) []*Bond {
+	return s.adjacency[agent]
+}
+
+// BondBetween returns the bond joining a and b, or nil if they are not bonded
+func (s State) BondBetween(a, b *Agent) *Bond {
+	for _, bond := range s.adjacency[a] {
+		if bond.Other(a) == b {
+			return bond
+		}
+	}
+	return nil
+}
+
+// springForce is the sum of the spring forces of the bonds of State.Agents[index]
+func (s State) springForce(index int) world.Vector {
+	agent := s.Agents[index]
+	force := s.Velocities.ZeroVector()
+	for _, bond := range s.adjacency[agent] {
+		deltaX, deltaY, extension := bond.extension(s.CoordinateSystem)
+		pull := s.Velocities.NewVector(deltaX, deltaY).SetMag(bond.Stiffness * extension)
+		if bond.B == agent {
+			pull.Scale(-1)
+		}
+		force.Accumulate(force, pull)
+	}
+	return *force
+}
+
+// updateBonds breaks every bond that is strained beyond its breaking strain
+func (s *State) updateBonds() {
+	for _, bond := range append([]*Bond{}, s.Bonds...) {
+		if bond.BreakingStrain > 0 && bond.strain(s.CoordinateSystem) > bond.BreakingStrain {
+			s.BreakBond(bond)
+		}
+	}
+}
+
+// without returns bonds with bond removed, preserving order
+func without(bonds []*Bond, bond *Bond) []*Bond {
+	remaining := make([]*Bond, 0, len(bonds))
+	for _, other := range bonds {
+		if other != bond {
+			remaining = append(remaining, other)
+		}
+	}
+	return remaining
+}
@@ -0,0 +1,106 @@
+package agents
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestState_FormBond_IsIdempotent(t *testing.T) {
+	state := pair()
+	a, b := state.Agents[0], state.Agents[1]
+
+	first := state.FormBond(a, b, BondSpec{RestLength: 1, Stiffness: 1})
+	second := state.FormBond(b, a, BondSpec{RestLength: 2, Stiffness: 2})
+
+	assert.Same(t, first, second)
+	assert.Len(t, state.Bonds, 1)
+	assert.Equal(t, []BondEvent{{Kind: BondFormed, Bond: first}}, state.BondEvents)
+	assert.Same(t, first, state.BondBetween(a, b))
+	assert.Same(t, b, first.Other(a))
+}
+
+func TestState_springForce_AcrossSeam(t *testing.T) {
+	state := pair()
+	state.FormBond(state.Agents[0], state.Agents[1], BondSpec{RestLength: 1, Stiffness: 2})
+
+	onA, onB := state.springForce(0), state.springForce(1)
+
+	assert.InDelta(t, -6.0, onA.X, MaxPrecision, "A should be pulled across the seam towards B")
+	assert.InDelta(t, 6.0, onB.X, MaxPrecision, "B should be pulled across the seam towards A")
+	assert.InDelta(t, 0.0, onA.Y, MaxPrecision)
+}
+
+func TestState_springForce_CompressedBondsPushApart(t *testing.T) {
+	state := pair()
+	state.FormBond(state.Agents[0], state.Agents[1], BondSpec{RestLength: 10, Stiffness: 1})
+
+	onA := state.springForce(0)
+
+	assert.InDelta(t, 6.0, onA.X, MaxPrecision)
+}
+
+func TestState_BreakBond(t *testing.T) {
+	state := pair()
+	a, b := state.Agents[0], state.Agents[1]
+	bond := state.FormBond(a, b, BondSpec{RestLength: 1, Stiffness: 1})
+
+	state.BreakBond(bond)
+
+	assert.Empty(t, state.Bonds)
+	assert.Empty(t, state.BondsOf(a))
+	assert.Nil(t, state.BondBetween(a, b))
+	assert.Equal(t, BondEvent{Kind: BondBroken, Bond: bond}, state.BondEvents[1])
+}
+
+func TestScenario_Step_BreaksOverstrainedBonds(t *testing.T) {
+	scenario := InitialiseScenario(time.Second / 10)
+	a, b := scenario.state.Agents[0], scenario.state.Agents[1]
+	a.Position.X, b.Position.X, a.Position.Y, b.Position.Y = 0, 100, 0, 0
+	bond := scenario.state.FormBond(a, b, BondSpec{RestLength: 10, Stiffness: 0, BreakingStrain: 2})
+
+	scenario.Step()
+
+	assert.Equal(t, []BondEvent{{Kind: BondBroken, Bond: bond}}, scenario.BondEvents())
+}
+
+func TestState_updateBonds_StrainWithoutRestLength(t *testing.T) {
+	state := pair()
+	a, b := state.Agents[0], state.Agents[1]
+	slack := state.FormBond(a, b, BondSpec{BreakingStrain: 5})
+
+	state.updateBonds()
+	assert.Equal(t, []*Bond{slack}, state.Bonds, "a bond with no rest length is strained by its extension")
+
+	slack.BreakingStrain = 3
+	state.updateBonds()
+	assert.Empty(t, state.Bonds)
+}
+
+func TestState_Clone_RemapsBonds(t *testing.T) {
+	state := pair()
+	state.FormBond(state.Agents[0], state.Agents[1], BondSpec{RestLength: 1, Stiffness: 1})
+
+	clone := state.Clone()
+
+	assert.Len(t, clone.Bonds, 1)
+	assert.Same(t, clone.Agents[0], clone.Bonds[0].A)
+	assert.Same(t, clone.Agents[1], clone.Bonds[0].B)
+	assert.Empty(t, clone.BondEvents)
+}
+
+func TestLover_SeparatedWhenBondBreaks(t *testing.T) {
+	scenario := couple(5, DefaultLover())
+	a, b := scenario.state.Agents[0], scenario.state.Agents[1]
+	for i := 0; i < 100 && a.Lover.Single(); i++ {
+		scenario.Step()
+	}
+	assert.NotNil(t, scenario.state.BondBetween(a, b))
+
+	a.Position.X = b.Position.X + 200
+	scenario.Step()
+
+	assert.Nil(t, scenario.state.BondBetween(a, b))
+	assert.True(t, a.Lover.Single())
+	assert.True(t, b.Lover.Single())
+}
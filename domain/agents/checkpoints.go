@@ -0,0 +1,94 @@
+package agents
+
+import (
+	"log"
+	"math"
+	"sort"
+	"time"
+)
+
+// DefaultCheckpointInterval is a reasonable interval for WithCheckpoints, replaying from a
+// checkpoint takes at most ten seconds of steps while the Scenario has fewer than its limit
+const DefaultCheckpointInterval = 10 * time.Second
+
+// DefaultCheckpointLimit is a reasonable limit for WithCheckpoints, it holds an hour of checkpoints
+// at the DefaultCheckpointInterval before any are thinned
+const DefaultCheckpointLimit = 360
+
+// WithCheckpoints enables the checkpoints that GetFrameAt replays earlier times from, taken every
+// interval of simulated time. Scenarios have none unless this is given. Replaying takes up to one
+// interval of steps, so shorter intervals trade memory for faster scrubbing. Zero disables
+// checkpoints.
+//
+// At most limit checkpoints are kept, and at least two. Once there are more, the older checkpoints
+// are thinned so that the gaps between them grow with their age: the recent past stays quick to
+// scrub to, and the distant past takes longer to replay.
+//
+// Replay assumes the Scenario is only changed by stepping it. Checkpoints are disabled, and the
+// reason logged, if the Scenario cannot be snapshotted because an agent has an unregistered
+// Behaviour.
+func WithCheckpoints(interval time.Duration, limit int) Option {
+	return func(s *Scenario) {
+		if limit < 2 {
+			limit = 2
+		}
+		s.checkpointInterval, s.checkpointLimit = interval, limit
+	}
+}
+
+// checkpoint records a Snapshot of the Scenario as it is now, thinning the checkpoints if there
+// are more than the limit
+func (s *Scenario) checkpoint() {
+	snapshot, err := s.Snapshot()
+	if err != nil {
+		log.Printf("checkpoints disabled at %v: %v", s.Time, err)
+		s.checkpointInterval = 0
+		return
+	}
+	s.checkpoints = append(s.checkpoints, snapshot)
+	s.nextCheckpoint = s.Time + s.checkpointInterval
+	for len(s.checkpoints) > s.checkpointLimit {
+		s.thin()
+	}
+}
+
+// thin removes the checkpoint whose removal leaves the smallest gap relative to how long ago the
+// gap began. The first and latest checkpoints are always kept, so that every time since the start
+// can be replayed and the latest replayed quickly.
+func (s *Scenario) thin() {
+	removed, least := 1, math.Inf(1)
+	for index := 1; index < len(s.checkpoints)-1; index++ {
+		before, after := s.checkpoints[index-1].Time, s.checkpoints[index+1].Time
+		if cost := float64(after-before) / float64(s.Time-before); cost < least {
+			removed, least = index, cost
+		}
+	}
+	s.checkpoints = append(s.checkpoints[:removed], s.checkpoints[removed+1:]...)
+}
+
+// replayTo returns a Scenario that has been stepped from the latest checkpoint at or before
+// target until it reaches target. The previous replay is continued if it is between the two,
+// so scrubbing forward through the past does not restore a checkpoint every time. If there is
+// no such checkpoint the Scenario itself is returned.
+func (s *Scenario) replayTo(target time.Duration) *Scenario {
+	latest := sort.Search(len(s.checkpoints), func(index int) bool {
+		return s.checkpoints[index].Time > target
+	}) - 1
+	if latest < 0 {
+		return s
+	}
+	checkpoint := s.checkpoints[latest]
+	if s.replay == nil || s.replay.Time < checkpoint.Time || s.replay.Time > target {
+		replay, err := Restore(checkpoint)
+		if err != nil {
+			// Checkpoints are Snapshots of this Scenario, which always restore
+			panic(err)
+		}
+		replay.workers, replay.state.pool = s.workers, s.state.pool
+		s.replay = replay
+	}
+	for s.replay.Time < target {
+		s.replay.Step()
+	}
+	return s.replay
+}
@@ -0,0 +1,64 @@
+package agents
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+	"tjweldon/archetypal-agents/utils"
+)
+
+func TestScenario_GetFrameAt_ReplaysThePast(t *testing.T) {
+	scenario := InitialiseScenario(
+		time.Second/60,
+		WithFlocking(DefaultFlocking()),
+		WithLovers(10, DefaultLover()),
+		WithRulers(3, DefaultRuler()),
+		WithTurbulence(6, 5),
+		WithCheckpoints(time.Second, DefaultCheckpointLimit),
+		WithSeed(3),
+	)
+	frames := []Frame{scenario.state.Frame()}
+	for range [300]any{} {
+		frames = append(frames, scenario.GetNextFrame())
+	}
+	now := scenario.Time
+
+	// Halfway between steps, in a random order that scrubs both ways
+	random := utils.NewRandom(1)
+	for range [20]any{} {
+		step := random.Int(1, len(frames)-2)
+		at := (time.Duration(step)*scenario.DeltaT - scenario.DeltaT/2).Seconds()
+		assert.Equal(t, frames[step], scenario.GetFrameAt(at), "step %d", step)
+	}
+	assert.Equal(t, now, scenario.Time)
+	assert.Equal(t, frames[len(frames)-1], scenario.GetFrameAt(now.Seconds()))
+	assert.Len(t, scenario.checkpoints, 5)
+}
+
+func TestScenario_checkpoint_ThinsOlderCheckpoints(t *testing.T) {
+	scenario := InitialiseScenario(time.Second/4, WithPopulation(5), WithCheckpoints(time.Second/4, 8), WithSeed(1))
+	frames := []Frame{scenario.state.Frame()}
+	for range [400]any{} {
+		frames = append(frames, scenario.GetNextFrame())
+	}
+
+	assert.Len(t, scenario.checkpoints, 8)
+	assert.Zero(t, scenario.checkpoints[0].Time, "the start is kept")
+	assert.Equal(t, scenario.Time-scenario.DeltaT, scenario.checkpoints[7].Time, "the latest is kept")
+	for index := 2; index < len(scenario.checkpoints); index++ {
+		older := scenario.checkpoints[index-1].Time - scenario.checkpoints[index-2].Time
+		newer := scenario.checkpoints[index].Time - scenario.checkpoints[index-1].Time
+		assert.GreaterOrEqual(t, older, newer, "older checkpoints are further apart")
+	}
+	for _, step := range []int{3, 150, 399} {
+		assert.Equal(t, frames[step], scenario.GetFrameAt((time.Duration(step) * scenario.DeltaT).Seconds()), "step %d", step)
+	}
+}
+
+func TestScenario_GetFrameAt_WithoutCheckpoints(t *testing.T) {
+	scenario := InitialiseScenario(time.Second / 4)
+	scenario.GetFrameAt(2)
+
+	assert.Equal(t, scenario.state.Frame(), scenario.GetFrameAt(1))
+	assert.Empty(t, scenario.checkpoints)
+}
@@ -0,0 +1,145 @@
+package agents
+
+import "sort"
+
+// Clustering groups the agents with DBSCAN every Interval steps. Agents within Radius of each other
+// are density connected if one of them has at least MinPoints agents, itself included, within
+// Radius, and each cluster is a maximal set of density connected agents. Distances are measured by
+// the Metric of the position space, so clusters that straddle a seam of a periodic world are whole.
+type Clustering struct {
+	Radius    float64
+	MinPoints int
+	Interval  int // Steps between clusterings, zero or one clusters every step
+}
+
+// DefaultClustering returns a Clustering that finds flocks of the default flocking rules a few
+// times a second
+func DefaultClustering() Clustering {
+	return Clustering{Radius: 25, MinPoints: 4, Interval: 10}
+}
+
+// WithClustering groups the agents of the Scenario every Clustering.Interval steps, from when it
+// is initialised. The group each agent is in is given by Agent.Group.
+func WithClustering(clustering Clustering) Option {
+	return populate(func(s *Scenario) {
+		s.state.Clustering = &clustering
+	})
+}
+
+// Clusters finds the clusters of the agents with DBSCAN (see Clustering). Each cluster is the
+// indices into State.Agents of its members in ascending order, and the clusters are in order of
+// their least member. Agents in no cluster are noise.
+func (s *State) Clusters(radius float64, minPoints int) [][]int {
+	s.reindex()
+	const unvisited, noise = -2, -1
+	labels := make([]int, s.Population())
+	for index := range labels {
+		labels[index] = unvisited
+	}
+
+	clusters := make([][]int, 0)
+	for index := range s.Agents {
+		if labels[index] != unvisited {
+			continue
+		}
+		neighbours := s.Neighbours(index, radius)
+		if len(neighbours)+1 < minPoints {
+			labels[index] = noise
+			continue
+		}
+
+		// Grow the cluster out from the core agent through every core agent it reaches
+		cluster := len(clusters)
+		labels[index] = cluster
+		members := []int{index}
+		frontier := neighbours
+		for len(frontier) > 0 {
+			next := frontier[0]
+			frontier = frontier[1:]
+			if labels[next] >= 0 {
+				continue
+			}
+			reached := labels[next] == unvisited
+			labels[next] = cluster
+			members = append(members, next)
+			if !reached {
+				// Noise on the border of the cluster, it was not a core agent
+				continue
+			}
+			if around := s.Neighbours(next, radius); len(around)+1 >= minPoints {
+				frontier = append(frontier, around...)
+			}
+		}
+		sort.Ints(members)
+		clusters = append(clusters, members)
+	}
+	return clusters
+}
+
+// regroup clusters the agents and sets the Group of each. Every cluster keeps the ID of the group
+// the most of its members were in before, unless a larger share of another cluster claims it, and
+// the remaining clusters are given new IDs. Agents in no cluster are in group zero.
+func (s *State) regroup() {
+	clusters := s.Clusters(s.Clustering.Radius, s.Clustering.MinPoints)
+
+	type overlap struct {
+		cluster, group, members int
+	}
+	overlaps := make([]overlap, 0)
+	for cluster, members := range clusters {
+		counts := make(map[int]int)
+		for _, member := range members {
+			if group := s.Agents[member].Group; group != 0 {
+				counts[group]++
+			}
+		}
+		for group, count := range counts {
+			overlaps = append(overlaps, overlap{cluster: cluster, group: group, members: count})
+		}
+	}
+	// Match the largest overlaps first, ties broken by order so that grouping is deterministic
+	sort.Slice(overlaps, func(i, j int) bool {
+		a, b := overlaps[i], overlaps[j]
+		if a.members != b.members {
+			return a.members > b.members
+		}
+		if a.cluster != b.cluster {
+			return a.cluster < b.cluster
+		}
+		return a.group < b.group
+	})
+
+	groups := make([]int, len(clusters))
+	taken := make(map[int]bool)
+	for _, overlap := range overlaps {
+		if groups[overlap.cluster] == 0 && !taken[overlap.group] {
+			groups[overlap.cluster], taken[overlap.group] = overlap.group, true
+		}
+	}
+	for cluster := range groups {
+		if groups[cluster] == 0 {
+			s.groups++
+			groups[cluster] = s.groups
+		}
+	}
+
+	for _, agent := range s.Agents {
+		agent.Group = 0
+	}
+	for cluster, members := range clusters {
+		for _, member := range members {
+			s.Agents[member].Group = groups[cluster]
+		}
+	}
+}
+
+// cluster regroups the agents if the Scenario has a Clustering and it is due at the current step
+func (s *Scenario) cluster() {
+	clustering := s.state.Clustering
+	if clustering == nil || s.DeltaT <= 0 {
+		return
+	}
+	if clustering.Interval <= 1 || int(s.Time/s.DeltaT)%clustering.Interval == 0 {
+		s.state.regroup()
+	}
+}
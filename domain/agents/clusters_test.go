@@ -0,0 +1,100 @@
+package agents
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+	"tjweldon/archetypal-agents/domain/world"
+)
+
+// flocks places stationary agents on the default toroid at the given x coordinates along y = 200
+func flocks(xs ...float64) *State {
+	toroid, plane := world.NewEuclideanToroid(width, height), world.NewEuclideanPlane()
+	state := NewState(toroid, plane)
+	for _, x := range xs {
+		state.Add(&Agent{Position: toroid.NewVector(x, 200), Velocity: plane.ZeroVector()})
+	}
+	state.Clustering = &Clustering{Radius: 10, MinPoints: 3}
+	return state
+}
+
+// groupsOf returns the Group of every agent
+func groupsOf(state *State) []int {
+	groups := make([]int, state.Population())
+	for index, agent := range state.Agents {
+		groups[index] = agent.Group
+	}
+	return groups
+}
+
+func TestState_Clusters_AcrossTheSeam(t *testing.T) {
+	state := flocks(795, 400, 3, 405, 200, 410, width-12, 8)
+
+	assert.Equal(t, [][]int{{0, 2, 6, 7}, {1, 3, 5}}, state.Clusters(10, 3))
+}
+
+func TestState_Clusters_BorderAgents(t *testing.T) {
+	// The agent at 14 is within reach of a core agent but has too few neighbours to be one itself,
+	// so the cluster does not reach the agent at 22
+	state := flocks(0, 2, 4, 6, 14, 22)
+
+	assert.Equal(t, [][]int{{0, 1, 2, 3, 4}}, state.Clusters(10, 4))
+}
+
+func TestState_regroup_KeepsIDs(t *testing.T) {
+	state := flocks(100, 105, 110, 300, 305, 310)
+	state.regroup()
+	assert.Equal(t, []int{1, 1, 1, 2, 2, 2}, groupsOf(state))
+
+	// The first flock moves on and one of the second joins newcomers, taking the second's ID with it
+	for _, agent := range state.Agents[:3] {
+		agent.Position.X += 50
+	}
+	state.Agents[5].Position.X = 500
+	state.Add(&Agent{Position: state.CoordinateSystem.NewVector(505, 200), Velocity: state.Velocities.ZeroVector()})
+	state.Add(&Agent{Position: state.CoordinateSystem.NewVector(510, 200), Velocity: state.Velocities.ZeroVector()})
+	state.Add(&Agent{Position: state.CoordinateSystem.NewVector(515, 200), Velocity: state.Velocities.ZeroVector()})
+	state.regroup()
+	assert.Equal(t, []int{1, 1, 1, 0, 0, 2, 2, 2, 2}, groupsOf(state), "the remains of the second flock are too few to be a cluster")
+
+	// The flocks merge, and the merged flock takes the ID of the larger
+	for _, agent := range state.Agents[:3] {
+		agent.Position.X += 340
+	}
+	state.regroup()
+	assert.Equal(t, []int{2, 2, 2, 0, 0, 2, 2, 2, 2}, groupsOf(state))
+
+	// The flocks part, and the one that lost its ID is given a new one
+	for _, agent := range state.Agents[:3] {
+		agent.Position.X -= 150
+	}
+	state.regroup()
+	assert.Equal(t, []int{3, 3, 3, 0, 0, 2, 2, 2, 2}, groupsOf(state))
+}
+
+func TestScenario_Step_ClustersEveryInterval(t *testing.T) {
+	scenario := InitialiseScenario(
+		time.Second/10,
+		WithPopulation(0),
+		WithAgents(40, Clusters{Count: 2, Spread: 5}),
+		WithClustering(Clustering{Radius: 10, MinPoints: 3, Interval: 5}),
+		WithSeed(1),
+	)
+	frame := scenario.state.Frame()
+	assert.NotZero(t, frame[0].Group, "the agents are clustered when the Scenario is initialised")
+
+	for range [4]any{} {
+		scenario.Step()
+		scenario.state.Agents[0].Group = 0
+	}
+	assert.Zero(t, scenario.state.Frame()[0].Group, "the agents are only clustered every Interval steps")
+
+	scenario.Step()
+	grouped := 0
+	for _, coords := range scenario.state.Frame() {
+		if coords.Group != 0 {
+			grouped++
+		}
+	}
+	assert.Greater(t, grouped, 30)
+}
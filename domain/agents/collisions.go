@@ -0,0 +1,123 @@
+package agents
+
+import "math"
+
+// Body gives an agent physical extent, agents with bodies cannot overlap one another once the
+// Scenario has Collisions
+type Body struct {
+	Radius float64
+	Mass   float64 // Zero is immovable
+}
+
+// DefaultBody returns a Body a little smaller than the separation distance of the flocking rules
+func DefaultBody() Body {
+	return Body{Radius: 4, Mass: 1}
+}
+
+// inverseMass is the reciprocal of the mass of the Body, zero for an immovable Body
+func (b Body) inverseMass() float64 {
+	if b.Mass <= 0 {
+		return 0
+	}
+	return 1 / b.Mass
+}
+
+// Collisions resolves overlaps between agents with bodies at the end of every step. Agents that
+// overlap are pushed apart along the line between their centres, in inverse proportion to their
+// masses, and if they are approaching they exchange an impulse along that line.
+type Collisions struct {
+	// Restitution is the ratio of the speeds at which the agents separate and approach, one is
+	// elastic and conserves kinetic energy, zero is perfectly inelastic and the agents move on
+	// together
+	Restitution float64
+}
+
+// WithBodies gives every agent of the given archetypes added to the Scenario before it a Body. No
+// archetypes means agents of every archetype, agents without one are points that collide with
+// nothing.
+func WithBodies(body Body, archetypes ...string) Option {
+	return populate(func(s *Scenario) {
+		for _, agent := range s.state.ofArchetypes(archetypes) {
+			body := body
+			agent.Body = &body
+		}
+	})
+}
+
+// WithCollisions makes agents with bodies collide with the given restitution, without it they pass
+// through each other
+func WithCollisions(restitution float64) Option {
+	return populate(func(s *Scenario) {
+		s.state.Collisions = &Collisions{Restitution: restitution}
+	})
+}
+
+// collide resolves the overlaps between agents with bodies. The pairs are found with the spatial
+// index and resolved in turn, in order of their indices, so the result is deterministic.
+func (s *State) collide() {
+	if s.Collisions == nil {
+		return
+	}
+	largest := 0.0
+	for _, agent := range s.Agents {
+		if agent.Body != nil {
+			largest = math.Max(largest, agent.Body.Radius)
+		}
+	}
+	if largest <= 0 {
+		return
+	}
+
+	s.reindex()
+	for index, agent := range s.Agents {
+		if agent.Body == nil || agent.Body.Radius <= 0 {
+			continue
+		}
+		for _, other := range s.Neighbours(index, agent.Body.Radius+largest) {
+			if other > index && s.Agents[other].Body != nil {
+				s.Collisions.resolve(s, agent, s.Agents[other])
+			}
+		}
+	}
+}
+
+// resolve separates a pair of agents with bodies if they overlap, and exchanges an impulse between
+// them if they are approaching
+func (c Collisions) resolve(s *State, a, b *Agent) {
+	contact := a.Body.Radius + b.Body.Radius
+	distance := s.CoordinateSystem.Metric(a.Position, b.Position)
+	inverseA, inverseB := a.Body.inverseMass(), b.Body.inverseMass()
+	if distance >= contact || inverseA+inverseB == 0 {
+		return
+	}
+
+	// The unit normal from a to b, agents at the same point are separated along the x axis
+	normalX, normalY := 1.0, 0.0
+	if distance > 0 {
+		deltaX, deltaY := s.CoordinateSystem.GeodesicDiff(a.Position.X, b.Position.X, a.Position.Y, b.Position.Y)
+		normalX, normalY = deltaX/distance, deltaY/distance
+	}
+
+	overlap := contact - distance
+	s.displace(a, -normalX*overlap*inverseA/(inverseA+inverseB), -normalY*overlap*inverseA/(inverseA+inverseB))
+	s.displace(b, normalX*overlap*inverseB/(inverseA+inverseB), normalY*overlap*inverseB/(inverseA+inverseB))
+
+	approach := (b.Velocity.X-a.Velocity.X)*normalX + (b.Velocity.Y-a.Velocity.Y)*normalY
+	if approach >= 0 {
+		return
+	}
+	impulse := -(1 + c.Restitution) * approach / (inverseA + inverseB)
+	a.Velocity.X, a.Velocity.Y = a.Velocity.X-impulse*inverseA*normalX, a.Velocity.Y-impulse*inverseA*normalY
+	b.Velocity.X, b.Velocity.Y = b.Velocity.X+impulse*inverseB*normalX, b.Velocity.Y+impulse*inverseB*normalY
+}
+
+// displace moves an agent by (deltaX, deltaY) unless that would take it through a wall of the Maze
+func (s *State) displace(agent *Agent, deltaX, deltaY float64) {
+	if s.Maze != nil {
+		if _, _, crosses := s.Maze.Crossing(agent.Position, deltaX, deltaY); crosses {
+			return
+		}
+	}
+	displacement := s.Velocities.NewVector(deltaX, deltaY)
+	agent.Position.Accumulate(agent.Position, displacement)
+}
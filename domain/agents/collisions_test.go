@@ -0,0 +1,107 @@
+package agents
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+	"tjweldon/archetypal-agents/worlds"
+)
+
+// collision is two agents with bodies of radius 2 either side of the seam of the default toroid,
+// overlapping by 1 and approaching each other along the x axis
+func collision(restitution, massA, massB float64, options ...Option) (*Scenario, *Agent, *Agent) {
+	scenario := InitialiseScenario(time.Second/10, append([]Option{WithPopulation(2), WithCollisions(restitution)}, options...)...)
+	a, b := scenario.state.Agents[0], scenario.state.Agents[1]
+	a.Position.X, a.Position.Y, a.Velocity.X = width-1.5, 200, 3
+	b.Position.X, b.Position.Y, b.Velocity.X = 1.5, 200, -1
+	a.Body, b.Body = &Body{Radius: 2, Mass: massA}, &Body{Radius: 2, Mass: massB}
+	return scenario, a, b
+}
+
+func TestCollisions_Elastic(t *testing.T) {
+	scenario, a, b := collision(1, 1, 1)
+
+	scenario.state.collide()
+
+	assert.InDelta(t, -1.0, a.Velocity.X, MaxPrecision, "equal masses exchange velocities")
+	assert.InDelta(t, 3.0, b.Velocity.X, MaxPrecision)
+	assert.InDelta(t, 4.0, scenario.positions.Metric(a.Position, b.Position), MaxPrecision, "the bodies just touch")
+	x, _ := a.Position.Wrapped()
+	assert.InDelta(t, width-2, x, MaxPrecision, "each body is pushed back by half the overlap")
+}
+
+func TestCollisions_Inelastic(t *testing.T) {
+	scenario, a, b := collision(0, 3, 1)
+
+	scenario.state.collide()
+
+	assert.InDelta(t, 2.0, a.Velocity.X, MaxPrecision, "the bodies move on together, conserving momentum")
+	assert.InDelta(t, 2.0, b.Velocity.X, MaxPrecision)
+	x, _ := b.Position.Wrapped()
+	assert.InDelta(t, 2.25, x, MaxPrecision, "the lighter body is pushed back further")
+}
+
+func TestCollisions_Immovable(t *testing.T) {
+	scenario, a, b := collision(1, 1, 0)
+
+	scenario.state.collide()
+
+	assert.InDelta(t, -5.0, a.Velocity.X, MaxPrecision, "the body rebounds off the immovable one")
+	assert.InDelta(t, -1.0, b.Velocity.X, MaxPrecision)
+	x, _ := b.Position.Wrapped()
+	assert.InDelta(t, 1.5, x, MaxPrecision)
+}
+
+func TestCollisions_PointsPassThrough(t *testing.T) {
+	scenario, a, b := collision(1, 1, 1)
+	b.Body = nil
+
+	scenario.state.collide()
+
+	assert.InDelta(t, 3.0, a.Velocity.X, MaxPrecision)
+	assert.InDelta(t, -1.0, b.Velocity.X, MaxPrecision)
+}
+
+func TestCollisions_NotThroughWalls(t *testing.T) {
+	scenario, a, b := collision(1, 1, 1, WithWalls(worlds.Wall{X1: 1.8, Y1: 150, X2: 1.8, Y2: 250}))
+	b.Position.X, b.Velocity.X = 1.5, 0
+
+	scenario.state.collide()
+
+	x, _ := b.Position.Wrapped()
+	assert.InDelta(t, 1.5, x, MaxPrecision, "the body is not pushed through the wall")
+	x, _ = a.Position.Wrapped()
+	assert.InDelta(t, width-2, x, MaxPrecision)
+}
+
+func TestScenario_Step_CrowdSpreadsOut(t *testing.T) {
+	// Resting contacts overlap a little, so only count pairs that overlap by more than a tenth
+	overlaps := func(state *State) (count int) {
+		for index, agent := range state.Agents {
+			for _, other := range state.Neighbours(index, 1.8*agent.Body.Radius) {
+				if other > index {
+					count++
+				}
+			}
+		}
+		return count
+	}
+	scenario := InitialiseScenario(
+		time.Second/10,
+		WithPopulation(0),
+		WithAgents(60, Clusters{Count: 1, Spread: 10}),
+		WithBodies(DefaultBody()),
+		WithCollisions(0.5),
+		WithSeed(3),
+	)
+	scenario.state.reindex()
+	crowded := overlaps(scenario.state)
+
+	for range [50]any{} {
+		scenario.Step()
+	}
+
+	scenario.state.reindex()
+	assert.Greater(t, crowded, 100)
+	assert.Less(t, overlaps(scenario.state), crowded/10)
+}
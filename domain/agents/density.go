@@ -0,0 +1,47 @@
+package agents
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sort"
+	"tjweldon/archetypal-agents/utils"
+)
+
+// Density places agents at random with a probability density proportional to the brightness of an
+// image stretched over the bounds, so that bright regions are crowded and black ones are empty
+type Density struct {
+	columns, rows int
+	cumulative    []float64 // Running total of the brightness of the pixels, row by row
+}
+
+// NewDensity initialises a Density from the brightness of picture, which must not be entirely black
+func NewDensity(picture image.Image) (*Density, error) {
+	area := picture.Bounds()
+	density := &Density{columns: area.Dx(), rows: area.Dy(), cumulative: make([]float64, 0, area.Dx()*area.Dy())}
+	total := 0.0
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			total += float64(color.Gray16Model.Convert(picture.At(x, y)).(color.Gray16).Y)
+			density.cumulative = append(density.cumulative, total)
+		}
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("the image is black, so there is nowhere to place agents")
+	}
+	return density, nil
+}
+
+// Spawn implements Spawn
+func (d *Density) Spawn(group []*Agent, bounds Bounds, random *utils.Random) {
+	total := d.cumulative[len(d.cumulative)-1]
+	width, height := bounds.Width/float64(d.columns), bounds.Height/float64(d.rows)
+	for _, agent := range group {
+		// The first pixel whose running total exceeds a uniform draw, black pixels never do
+		draw := random.Float(0, total)
+		pixel := sort.Search(len(d.cumulative), func(index int) bool { return d.cumulative[index] > draw })
+		column, row := pixel%d.columns, pixel/d.columns
+		agent.Position.X = (float64(column) + random.Float64()) * width
+		agent.Position.Y = (float64(row) + random.Float64()) * height
+	}
+}
@@ -0,0 +1,149 @@
+package agents
+
+import (
+	"math"
+	"tjweldon/archetypal-agents/domain/world"
+	"tjweldon/archetypal-agents/worlds"
+)
+
+// Metabolism is an agent's energy budget. Staying alive costs energy, moving costs more in
+// proportion to the distance travelled, and energy is regained by eating from resource patches
+// the agent is in contact with. An agent whose energy runs out starves, or if it hibernates it
+// goes dormant: it stops steering and is revived by whatever it can eat where it stands.
+type Metabolism struct {
+	Energy    float64 // The current reserve
+	Capacity  float64 // The most energy the agent can store
+	Basal     float64 // Energy spent per second regardless of movement
+	Movement  float64 // Energy spent per unit of distance travelled under the agent's own power
+	Appetite  float64 // The most energy the agent can eat per second
+	Hibernate bool    // An agent with no energy goes dormant instead of starving
+}
+
+// DefaultMetabolism returns a Metabolism that starts full and lasts for about a hundred seconds at
+// full speed without eating
+func DefaultMetabolism() Metabolism {
+	return Metabolism{Energy: 100, Capacity: 100, Basal: 0.5, Movement: 0.05, Appetite: 20}
+}
+
+// Dormant reports whether the agent has run out of energy and is hibernating
+func (a *Agent) Dormant() bool {
+	return a.Metabolism != nil && a.Metabolism.Energy <= 0
+}
+
+// WithMetabolism gives every agent of the given archetypes added to the Scenario before it a
+// Metabolism. No archetypes means agents of every archetype.
+func WithMetabolism(metabolism Metabolism, archetypes ...string) Option {
+	return populate(func(s *Scenario) {
+		for _, agent := range s.state.ofArchetypes(archetypes) {
+			metabolism := metabolism
+			agent.Metabolism = &metabolism
+		}
+	})
+}
+
+// WithForaging adds Forage to the behaviours of every agent of the given archetypes added to the
+// Scenario before it, with the given perception radius and weight. No archetypes means agents of
+// every archetype. Each agent forages at its own maximum speed.
+func WithForaging(radius, weight float64, archetypes ...string) Option {
+	return populate(func(s *Scenario) {
+		for _, agent := range s.state.ofArchetypes(archetypes) {
+			speed := agent.MaxSpeed
+			if speed == 0 {
+				speed = maxSpeed
+			}
+			forage := Forage{Limits{MaxSpeed: speed, MaxForce: speed / 2}, radius}
+			agent.Behaviours = append(agent.Behaviours, WeightedBehaviour{Behaviour: forage, Weight: weight})
+		}
+	})
+}
+
+// WithPatches adds the given resource patches to the Scenario
+func WithPatches(patches ...worlds.Patch) Option {
+	return populate(func(s *Scenario) {
+		s.attachPatches(patches)
+	})
+}
+
+// WithResources scatters count full resource patches of the given radius, capacity and regrowth per
+// second over the world
+func WithResources(count int, radius, capacity, regrowth float64) Option {
+	return populate(func(s *Scenario) {
+		s.attachPatches(worlds.ScatterPatches(s.bounds.Width, s.bounds.Height, count, radius, capacity, regrowth, s.random))
+	})
+}
+
+// attachPatches adds patches to the Resources of the Scenario, creating them if there are none yet
+func (s *Scenario) attachPatches(patches []worlds.Patch) {
+	if s.state.Resources == nil {
+		s.state.Resources = worlds.NewResources(s.positions)
+	}
+	s.state.Resources.Patches = append(s.state.Resources.Patches, patches...)
+}
+
+// Patches returns the resource patches of the Scenario as they are now
+func (s *Scenario) Patches() []worlds.Patch {
+	if s.state.Resources == nil {
+		return []worlds.Patch{}
+	}
+	return append([]worlds.Patch{}, s.state.Resources.Patches...)
+}
+
+// Forage steers towards the nearest patch within Radius that has anything left to eat, more
+// urgently the hungrier the agent is. Agents without a Metabolism are always hungry.
+type Forage struct {
+	Limits
+	Radius float64
+}
+
+// Steer implements Behaviour
+func (f Forage) Steer(self *Agent, view View) world.Vector {
+	var nearest *world.Vector
+	nearestDistance := f.Radius
+	for _, patch := range view.Patches() {
+		if patch.Amount <= 0 {
+			continue
+		}
+		offset := view.Offset(view.Space().NewVector(patch.X, patch.Y))
+		if distance := offset.Mag(); distance < nearestDistance {
+			nearest, nearestDistance = &offset, distance
+		}
+	}
+	if nearest == nil {
+		return *view.Velocities().ZeroVector()
+	}
+	steering := f.steer(self, *nearest)
+	if metabolism := self.Metabolism; metabolism != nil && metabolism.Capacity > 0 {
+		steering.Scale(1 - metabolism.Energy/metabolism.Capacity)
+	}
+	return steering
+}
+
+// updateMetabolisms regrows the resources and then charges every agent for dt seconds of living and
+// moving, less what it eats. Agents that run out of energy starve, or if they hibernate are
+// stopped where they are.
+func (s *State) updateMetabolisms(dt float64) {
+	if s.Resources != nil {
+		s.Resources.Regrow(dt)
+	}
+	for _, agent := range append([]*Agent{}, s.Agents...) {
+		metabolism := agent.Metabolism
+		if metabolism == nil {
+			continue
+		}
+		if s.Resources != nil {
+			hunger := math.Max(0, metabolism.Capacity-metabolism.Energy)
+			metabolism.Energy += s.Resources.Consume(agent.Position, math.Min(metabolism.Appetite*dt, hunger))
+		}
+		metabolism.Energy -= metabolism.Basal*dt + metabolism.Movement*agent.Velocity.Mag()*dt
+		if metabolism.Energy > 0 {
+			continue
+		}
+
+		metabolism.Energy = 0
+		if !metabolism.Hibernate {
+			s.Remove(agent)
+			continue
+		}
+		agent.Velocity.X, agent.Velocity.Y = 0, 0
+	}
+}
@@ -0,0 +1,122 @@
+package agents
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+	"tjweldon/archetypal-agents/worlds"
+)
+
+// forager is a single agent moving at speed along the x axis with the given metabolism
+func forager(speed float64, metabolism Metabolism, options ...Option) (*Scenario, *Agent) {
+	scenario := InitialiseScenario(time.Second/10, append([]Option{WithPopulation(1), WithSeed(1)}, options...)...)
+	agent := scenario.state.Agents[0]
+	agent.Position.X, agent.Position.Y = 100, 200
+	agent.Velocity.X, agent.Velocity.Y = speed, 0
+	agent.Metabolism = &metabolism
+	return scenario, agent
+}
+
+func TestMetabolism_MovementDrainsEnergy(t *testing.T) {
+	metabolism := Metabolism{Energy: 10, Capacity: 10, Basal: 1, Movement: 0.5}
+	still, resting := forager(0, metabolism)
+	running, moving := forager(4, metabolism)
+
+	still.state.updateMetabolisms(1)
+	running.state.updateMetabolisms(1)
+
+	assert.InDelta(t, 9.0, resting.Metabolism.Energy, MaxPrecision)
+	assert.InDelta(t, 7.0, moving.Metabolism.Energy, MaxPrecision)
+}
+
+func TestMetabolism_EatsFromPatchesOnContact(t *testing.T) {
+	patch := worlds.Patch{X: 100, Y: 200, Radius: 5, Amount: 3, Capacity: 3}
+	scenario, agent := forager(0, Metabolism{Energy: 1, Capacity: 10, Appetite: 20}, WithPatches(patch))
+
+	scenario.Step()
+
+	assert.InDelta(t, 3.0, agent.Metabolism.Energy, MaxPrecision)
+	assert.InDelta(t, 1.0, scenario.Patches()[0].Amount, MaxPrecision)
+}
+
+func TestMetabolism_StarvingAgentsDie(t *testing.T) {
+	scenario, agent := forager(0, Metabolism{Energy: 0.25, Capacity: 10, Basal: 1})
+
+	scenario.Step()
+	scenario.Step()
+	assert.Equal(t, 1, scenario.state.Population())
+	scenario.Step()
+
+	assert.Empty(t, scenario.state.Agents)
+	assert.Equal(t, []LifeEvent{{Kind: Died, Agent: agent}}, scenario.LifeEvents())
+}
+
+func TestMetabolism_HibernatingAgentsGoDormant(t *testing.T) {
+	patch := worlds.Patch{X: 100, Y: 200, Radius: 5, Capacity: 10, Regrowth: 1}
+	scenario, agent := forager(5, Metabolism{Energy: 0.1, Capacity: 10, Movement: 1, Appetite: 20, Hibernate: true}, WithPatches(patch))
+	agent.Behaviours = []WeightedBehaviour{{Behaviour: Wander{Limits{MaxSpeed: 5, MaxForce: 5}, 0.5}, Weight: 1}}
+
+	scenario.Step()
+	assert.True(t, agent.Dormant())
+	assert.Zero(t, agent.Velocity.Mag())
+	steering := agent.Steer(scenario.state.NewView(0))
+	assert.Zero(t, steering.Mag())
+
+	scenario.Step()
+	assert.False(t, agent.Dormant(), "revived by the regrowth of the patch it stopped on")
+	assert.Equal(t, 1, scenario.state.Population())
+}
+
+func TestForage_SteersTowardsNearestPatchAcrossSeam(t *testing.T) {
+	scenario, agent := forager(0, DefaultMetabolism(), WithPatches(
+		worlds.Patch{X: 10, Y: 200, Radius: 5, Amount: 1},
+		worlds.Patch{X: 150, Y: 200, Radius: 5, Amount: 0},
+	))
+	agent.Position.X = 780
+	agent.Metabolism.Energy = 50
+	forage := Forage{Limits{MaxSpeed: 10, MaxForce: 10}, 100}
+
+	steering := forage.Steer(agent, scenario.state.NewView(0))
+
+	assert.InDelta(t, 5.0, steering.X, MaxPrecision, "half as hungry steers half as hard")
+	assert.InDelta(t, 0.0, steering.Y, MaxPrecision)
+}
+
+func TestScenario_Load_ContinuesForaging(t *testing.T) {
+	original := InitialiseScenario(
+		time.Second/10,
+		WithPopulation(30),
+		WithResources(10, 20, 30, 1),
+		WithMetabolism(Metabolism{Energy: 20, Capacity: 40, Basal: 0.5, Movement: 0.2, Appetite: 10}),
+		WithLifecycle(Lifecycle{Maturity: 1, Fertility: 0.1, Mutation: 0.1}),
+		WithSeed(5),
+	)
+	for _, agent := range original.state.Agents {
+		agent.Behaviours = []WeightedBehaviour{
+			{Behaviour: Forage{Limits{MaxSpeed: 10, MaxForce: 5}, 200}, Weight: 1},
+			{Behaviour: Wander{Limits{MaxSpeed: 10, MaxForce: 5}, 0.5}, Weight: 0.5},
+		}
+	}
+	for range [40]any{} {
+		original.Step()
+	}
+
+	var saved bytes.Buffer
+	assert.NoError(t, original.Save(&saved))
+	restored, err := Load(&saved)
+	assert.NoError(t, err)
+
+	for range [60]any{} {
+		assert.Equal(t, original.GetNextFrame(), restored.GetNextFrame())
+		assert.Equal(t, original.Patches(), restored.Patches())
+	}
+}
+
+func TestWithForaging_ForagesAtEachAgentsSpeed(t *testing.T) {
+	scenario := InitialiseScenario(time.Second/10, WithPopulation(1), WithLovers(1, DefaultLover()), WithForaging(80, 2))
+
+	plain, lover := scenario.state.Agents[0], scenario.state.Agents[1]
+	assert.Equal(t, WeightedBehaviour{Behaviour: Forage{Limits{MaxSpeed: maxSpeed, MaxForce: maxSpeed / 2}, 80}, Weight: 2}, plain.Behaviours[0])
+	assert.Equal(t, Forage{Limits{MaxSpeed: lover.MaxSpeed, MaxForce: lover.MaxSpeed / 2}, 80}, lover.Behaviours[len(lover.Behaviours)-1].Behaviour)
+}
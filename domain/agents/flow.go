@@ -0,0 +1,32 @@
+package agents
+
+import "tjweldon/archetypal-agents/worlds"
+
+// WithTurbulence immerses the Scenario in a turbulent FlowField of the given number of modes and
+// typical speed, which advects agents in addition to their own velocity
+func WithTurbulence(modes int, strength float64) Option {
+	return populate(func(s *Scenario) {
+		s.state.Flow = worlds.NewTurbulence(s.bounds.Width, s.bounds.Height, modes, strength, s.random)
+	})
+}
+
+// FlowSamples evaluates the Scenario's FlowField at the current time on a columns x rows grid, or
+// returns no samples if there is no flow
+func (s *Scenario) FlowSamples(columns, rows int) []worlds.FlowSample {
+	if s.state.Flow == nil {
+		return []worlds.FlowSample{}
+	}
+	return s.state.Flow.Sample(columns, rows, s.Time.Seconds())
+}
+
+// advect carries every agent along with the flow at time t for dt seconds
+func (s *State) advect(t, dt float64) {
+	if s.Flow == nil {
+		return
+	}
+	for _, agent := range s.Agents {
+		u, v := s.Flow.Velocity(agent.Position.X, agent.Position.Y, t)
+		drift := s.Velocities.NewVector(u*dt, v*dt)
+		agent.Position.Accumulate(agent.Position, drift)
+	}
+}
@@ -0,0 +1,32 @@
+package agents
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+	"tjweldon/archetypal-agents/worlds"
+)
+
+func TestScenario_Step_AdvectsAgents(t *testing.T) {
+	scenario := InitialiseScenario(time.Second / 10)
+	scenario.state.Flow = &worlds.FlowField{
+		Width: width, Height: height,
+		Modes: []worlds.Mode{{KX: 0, KY: 1, Amplitude: 100}},
+	}
+	agent := scenario.state.Agents[0]
+	agent.Position.X, agent.Position.Y = 100, 0
+	agent.Velocity.X, agent.Velocity.Y = 0, 0
+	u, v := scenario.state.Flow.Velocity(100, 0, 0)
+
+	scenario.Step()
+
+	x, y := agent.Position.Wrapped()
+	assert.Greater(t, u, 0.0)
+	assert.InDelta(t, 100+u/10, x, MaxPrecision)
+	assert.InDelta(t, v/10, y, MaxPrecision)
+}
+
+func TestScenario_FlowSamples(t *testing.T) {
+	assert.Empty(t, InitialiseScenario(time.Second).FlowSamples(4, 4))
+	assert.Len(t, InitialiseScenario(time.Second, WithTurbulence(3, 1)).FlowSamples(4, 2), 8)
+}
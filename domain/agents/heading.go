@@ -0,0 +1,60 @@
+package agents
+
+import (
+	"math"
+	"tjweldon/archetypal-agents/domain/world"
+)
+
+// WithTurnRate limits how fast every agent of the given archetypes added to the Scenario before it
+// can turn, in radians per second. No archetypes means agents of every archetype, agents without a
+// limit turn as fast as their behaviours steer them.
+func WithTurnRate(rate float64, archetypes ...string) Option {
+	return populate(func(s *Scenario) {
+		for _, agent := range s.state.ofArchetypes(archetypes) {
+			agent.TurnRate = rate
+		}
+	})
+}
+
+// face points the agent along its velocity, a stationary agent keeps its heading
+func (a *Agent) face() {
+	if a.Velocity.X != 0 || a.Velocity.Y != 0 {
+		a.Heading = math.Atan2(a.Velocity.Y, a.Velocity.X)
+	}
+}
+
+// limitTurn limits the acceleration of the agent so that the velocity it reaches in dt seconds is
+// turned no further from its heading than its TurnRate allows. The speed it reaches is kept.
+func (a *Agent) limitTurn(acceleration *world.Vector, dt float64) {
+	if a.TurnRate <= 0 || dt <= 0 {
+		return
+	}
+	x, y := a.Velocity.X+acceleration.X*dt, a.Velocity.Y+acceleration.Y*dt
+	speed := math.Hypot(x, y)
+	if speed == 0 {
+		return
+	}
+	turn := math.Remainder(math.Atan2(y, x)-a.Heading, 2*math.Pi)
+	if math.Abs(turn) <= a.TurnRate*dt {
+		return
+	}
+	direction := a.Heading + math.Copysign(a.TurnRate*dt, turn)
+	acceleration.X = (speed*math.Cos(direction) - a.Velocity.X) / dt
+	acceleration.Y = (speed*math.Sin(direction) - a.Velocity.Y) / dt
+}
+
+// turn brings the agent's heading round to its velocity at the end of a step of dt seconds and sets
+// its AngularVelocity to the rate it turned at. Walls and collisions may have turned the velocity
+// further than the TurnRate allows, the heading follows them.
+func (a *Agent) turn(dt float64) {
+	previous := a.Heading
+	a.face()
+	a.AngularVelocity = math.Remainder(a.Heading-previous, 2*math.Pi) / dt
+}
+
+// turn brings the heading of every agent round to its velocity, see Agent.turn
+func (s *State) turn(dt float64) {
+	s.ForEach(func(index int) {
+		s.Agents[index].turn(dt)
+	})
+}
@@ -0,0 +1,88 @@
+package agents
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+	"tjweldon/archetypal-agents/worlds"
+)
+
+// turning is a single agent moving along the x axis at speed 5 that is steered hard in the +y
+// direction
+func turning(options ...Option) (*Scenario, *Agent) {
+	scenario := InitialiseScenario(time.Second/10, append([]Option{WithPopulation(1)}, options...)...)
+	agent := scenario.state.Agents[0]
+	agent.Position.X, agent.Position.Y = 400, 200
+	agent.Velocity.X, agent.Velocity.Y, agent.Heading = 5, 0, 0
+	agent.Behaviours = []WeightedBehaviour{{Behaviour: constant{0, 100}, Weight: 1}}
+	return scenario, agent
+}
+
+func TestScenario_Step_HeadingFollowsVelocity(t *testing.T) {
+	scenario, agent := turning()
+
+	scenario.Step()
+
+	assert.InDelta(t, math.Atan2(10, 5), agent.Heading, MaxPrecision)
+	assert.InDelta(t, math.Atan2(10, 5)*10, agent.AngularVelocity, MaxPrecision)
+}
+
+func TestScenario_Step_TurnRateLimitsTurning(t *testing.T) {
+	scenario, agent := turning(WithTurnRate(math.Pi / 2))
+
+	scenario.Step()
+
+	assert.InDelta(t, math.Pi/20, agent.Heading, MaxPrecision)
+	assert.InDelta(t, math.Pi/2, agent.AngularVelocity, MaxPrecision)
+	assert.InDelta(t, math.Hypot(5, 10), agent.Velocity.Mag(), MaxPrecision, "the speed is kept")
+	assert.InDelta(t, math.Pi/20, math.Atan2(agent.Velocity.Y, agent.Velocity.X), MaxPrecision, "the agent moves the way it faces")
+	dx, dy := scenario.state.CoordinateSystem.GeodesicDiff(400, agent.Position.X, 200, agent.Position.Y)
+	assert.InDelta(t, math.Hypot(5, 10)/10, math.Hypot(dx, dy), MaxPrecision)
+	assert.InDelta(t, math.Pi/20, math.Atan2(dy, dx), MaxPrecision, "the agent moved the way it faces")
+
+	for range [9]any{} {
+		scenario.Step()
+	}
+	assert.Greater(t, agent.Heading, math.Pi/4, "the agent keeps turning towards the steering force")
+	assert.Less(t, agent.Heading, math.Pi/2)
+}
+
+func TestScenario_Step_TurnRateLimitsEveryIntegrator(t *testing.T) {
+	for name, integrator := range integrators {
+		scenario, agent := turning(WithTurnRate(math.Pi/2), WithIntegrator(integrator))
+
+		scenario.Step()
+
+		dx, dy := scenario.state.CoordinateSystem.GeodesicDiff(400, agent.Position.X, 200, agent.Position.Y)
+		assert.LessOrEqual(t, math.Atan2(dy, dx), math.Pi/20+MaxPrecision, name)
+		assert.LessOrEqual(t, agent.Heading, math.Pi/20+MaxPrecision, name)
+	}
+}
+
+func TestScenario_Step_StationaryAgentsKeepTheirHeading(t *testing.T) {
+	scenario, agent := turning()
+	agent.Behaviours, agent.Velocity.X, agent.Heading = nil, 0, 2
+
+	scenario.Step()
+
+	assert.Equal(t, 2.0, agent.Heading)
+	assert.Zero(t, agent.AngularVelocity)
+}
+
+func TestScenario_Step_WallsTurnAgentsBeyondTheirTurnRate(t *testing.T) {
+	scenario, agent := turning(WithTurnRate(math.Pi/2), WithWalls(worlds.Wall{X1: 400.2, Y1: 150, X2: 400.2, Y2: 250}))
+	agent.Behaviours = nil
+
+	scenario.Step()
+
+	assert.InDelta(t, math.Pi, math.Abs(agent.Heading), MaxPrecision, "the agent faces away from the wall")
+	assert.InDelta(t, -5.0, agent.Velocity.X, MaxPrecision)
+}
+
+func TestState_Frame_Heading(t *testing.T) {
+	scenario, agent := turning()
+	agent.Heading = 1.2345
+
+	assert.Equal(t, LPFloat{Value: 1.2345, Digits: 3}, scenario.state.Frame()[0].Heading)
+}
@@ -0,0 +1,120 @@
+package agents
+
+import "tjweldon/archetypal-agents/domain/world"
+
+// Acceleration evaluates the acceleration of every agent in a State. The result is in the
+// same order as State.Agents and its vectors belong to the velocity space.
+type Acceleration func(state *State) []world.Vector
+
+// Integrator is a numerical scheme that advances the positions and velocities of every agent
+// in a State through a timestep of dt seconds. Implementations must update positions through
+// world.Vector.Accumulate so that the sum respects the topology of the position space, and may
+// update the agents in parallel with State.ForEach.
+type Integrator interface {
+	Integrate(state *State, dt float64, acceleration Acceleration)
+}
+
+// ExplicitEuler is the forward Euler method. Positions are advanced using the velocity at the
+// start of the step, it is first order and does not conserve energy.
+type ExplicitEuler struct{}
+
+// Integrate implements Integrator
+func (ExplicitEuler) Integrate(state *State, dt float64, acceleration Acceleration) {
+	accelerations := acceleration(state)
+	state.ForEach(func(index int) {
+		agent := state.Agents[index]
+		displacement := agent.Velocity.Times(dt)
+		impulse := accelerations[index].Times(dt)
+		agent.Position.Accumulate(agent.Position, &displacement)
+		agent.Velocity.Accumulate(agent.Velocity, &impulse)
+	})
+}
+
+// SemiImplicitEuler (symplectic Euler) advances the velocity first and then moves each agent
+// using the updated velocity. It is first order but symplectic, so energy drift stays bounded.
+type SemiImplicitEuler struct{}
+
+// Integrate implements Integrator
+func (SemiImplicitEuler) Integrate(state *State, dt float64, acceleration Acceleration) {
+	accelerations := acceleration(state)
+	state.ForEach(func(index int) {
+		agent := state.Agents[index]
+		impulse := accelerations[index].Times(dt)
+		agent.Velocity.Accumulate(agent.Velocity, &impulse)
+		displacement := agent.Velocity.Times(dt)
+		agent.Position.Accumulate(agent.Position, &displacement)
+	})
+}
+
+// VelocityVerlet is second order and symplectic. The acceleration is evaluated twice per step,
+// the second time at the new positions with a velocity predicted by an Euler step.
+type VelocityVerlet struct{}
+
+// Integrate implements Integrator
+func (VelocityVerlet) Integrate(state *State, dt float64, acceleration Acceleration) {
+	initial := acceleration(state)
+	state.ForEach(func(index int) {
+		agent := state.Agents[index]
+		displacement := agent.Velocity.Times(dt)
+		correction := initial[index].Times(dt * dt / 2)
+		prediction := initial[index].Times(dt)
+		agent.Position.Accumulate(agent.Position, &displacement, &correction)
+		agent.Velocity.Accumulate(agent.Velocity, &prediction)
+	})
+
+	final := acceleration(state)
+	state.ForEach(func(index int) {
+		// Swap the predicted half of the impulse for the average of both accelerations
+		agent := state.Agents[index]
+		retraction := initial[index].Times(-dt / 2)
+		impulse := final[index].Times(dt / 2)
+		agent.Velocity.Accumulate(agent.Velocity, &retraction, &impulse)
+	})
+}
+
+// RungeKutta4 is the classical fourth order Runge-Kutta method. It is the most accurate of the
+// schemes for smooth forces but evaluates the acceleration four times per step and is not
+// symplectic, so energy drifts slowly over long runs.
+type RungeKutta4 struct{}
+
+// Integrate implements Integrator
+func (RungeKutta4) Integrate(state *State, dt float64, acceleration Acceleration) {
+	population := state.Population()
+	velocities := make([][]world.Vector, 4)
+	accelerations := make([][]world.Vector, 4)
+
+	// Each stage is evaluated on a displaced copy of the initial state
+	offsets := [4]float64{0, dt / 2, dt / 2, dt}
+	for stage, offset := range offsets {
+		trial := state
+		if stage > 0 {
+			trial = state.Clone()
+			trial.ForEach(func(index int) {
+				agent := trial.Agents[index]
+				displacement := velocities[stage-1][index].Times(offset)
+				impulse := accelerations[stage-1][index].Times(offset)
+				agent.Position.Accumulate(agent.Position, &displacement)
+				agent.Velocity.Accumulate(agent.Velocity, &impulse)
+			})
+		}
+		velocities[stage] = make([]world.Vector, population)
+		for index, agent := range trial.Agents {
+			velocities[stage][index] = *agent.Velocity.Copy()
+		}
+		accelerations[stage] = acceleration(trial)
+	}
+
+	weights := [4]float64{dt / 6, dt / 3, dt / 3, dt / 6}
+	state.ForEach(func(index int) {
+		agent := state.Agents[index]
+		displacements, impulses := make([]*world.Vector, 5), make([]*world.Vector, 5)
+		displacements[0], impulses[0] = agent.Position, agent.Velocity
+		for stage, weight := range weights {
+			displacement := velocities[stage][index].Times(weight)
+			impulse := accelerations[stage][index].Times(weight)
+			displacements[stage+1], impulses[stage+1] = &displacement, &impulse
+		}
+		agent.Position.Accumulate(displacements...)
+		agent.Velocity.Accumulate(impulses...)
+	})
+}
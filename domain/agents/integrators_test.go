@@ -0,0 +1,90 @@
+package agents
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"tjweldon/archetypal-agents/domain/world"
+)
+
+var integrators = map[string]Integrator{
+	"ExplicitEuler":     ExplicitEuler{},
+	"SemiImplicitEuler": SemiImplicitEuler{},
+	"VelocityVerlet":    VelocityVerlet{},
+	"RungeKutta4":       RungeKutta4{},
+}
+
+// oscillator is a single agent on a unit spring about the origin of the euclidean plane
+func oscillator() *State {
+	plane := world.NewEuclideanPlane()
+	agent := &Agent{Position: plane.NewVector(1, 0), Velocity: plane.NewVector(0, 1)}
+	return &State{CoordinateSystem: plane, Velocities: plane, Agents: []*Agent{agent}}
+}
+
+func spring(state *State) []world.Vector {
+	accelerations := make([]world.Vector, state.Population())
+	for index, agent := range state.Agents {
+		accelerations[index] = agent.Position.Times(-1)
+	}
+	return accelerations
+}
+
+func energy(state *State) float64 {
+	agent := state.Agents[0]
+	return (agent.Position.Dot(agent.Position) + agent.Velocity.Dot(agent.Velocity)) / 2
+}
+
+func integrate(integrator Integrator, state *State, periods int, stepsPerPeriod int) {
+	dt := 2 * math.Pi / float64(stepsPerPeriod)
+	for i := 0; i < periods*stepsPerPeriod; i++ {
+		integrator.Integrate(state, dt, spring)
+	}
+}
+
+func TestIntegrators_OscillatorReturnsAfterOnePeriod(t *testing.T) {
+	tolerances := map[string]float64{
+		"ExplicitEuler":     5e-2,
+		"SemiImplicitEuler": 1e-2,
+		"VelocityVerlet":    1e-4,
+		"RungeKutta4":       1e-9,
+	}
+	for name, integrator := range integrators {
+		state := oscillator()
+		integrate(integrator, state, 1, 1000)
+
+		agent := state.Agents[0]
+		assert.InDelta(t, 1.0, agent.Position.X, tolerances[name], name)
+		assert.InDelta(t, 0.0, agent.Position.Y, tolerances[name], name)
+	}
+}
+
+func TestIntegrators_SymplecticSchemesHaveBoundedEnergyDrift(t *testing.T) {
+	for _, name := range []string{"SemiImplicitEuler", "VelocityVerlet"} {
+		state := oscillator()
+		initial := energy(state)
+		integrate(integrators[name], state, 50, 100)
+
+		assert.InDelta(t, initial, energy(state), 0.05*initial, name)
+	}
+
+	state := oscillator()
+	initial := energy(state)
+	integrate(ExplicitEuler{}, state, 50, 100)
+	assert.Greater(t, energy(state), 2*initial, "ExplicitEuler")
+}
+
+func TestIntegrators_WrapOnToroid(t *testing.T) {
+	for name, integrator := range integrators {
+		toroid, plane := world.NewEuclideanToroid(10, 10), world.NewEuclideanPlane()
+		agent := &Agent{Position: toroid.NewVector(9, 9), Velocity: plane.NewVector(2, 3)}
+		state := &State{CoordinateSystem: toroid, Velocities: plane, Agents: []*Agent{agent}}
+
+		integrator.Integrate(state, 1, func(state *State) []world.Vector {
+			return []world.Vector{*plane.ZeroVector()}
+		})
+
+		x, y := agent.Position.Wrapped()
+		assert.InDelta(t, 1.0, x, MaxPrecision, name)
+		assert.InDelta(t, 2.0, y, MaxPrecision, name)
+	}
+}
@@ -0,0 +1,198 @@
+package agents
+
+import "math"
+
+// Lifecycle describes how an agent ages and reproduces. Offspring are born beside their parent and
+// inherit its parameters, each scaled by a random factor drawn from a normal distribution about 1
+// with standard deviation Mutation, so that selection can act on them over generations.
+type Lifecycle struct {
+	Lifespan  float64 // Seconds the agent lives, zero means it never dies of old age
+	Maturity  float64 // Age in seconds at which the agent starts to reproduce
+	Fertility float64 // Expected offspring per second once mature, zero means the agent never reproduces
+	Mutation  float64 // Relative standard deviation of inherited parameters
+	Reserve   float64 // Energy the agent needs to reproduce, agents without a Metabolism need none
+}
+
+// LifeEventKind distinguishes the birth of an agent from its death
+type LifeEventKind string
+
+const (
+	Born LifeEventKind = "born"
+	Died LifeEventKind = "died"
+)
+
+// LifeEvent records an agent being added to or removed from a State
+type LifeEvent struct {
+	Kind  LifeEventKind
+	Agent *Agent
+}
+
+// WithLifecycle gives every agent of the given archetypes added to the Scenario before it a
+// Lifecycle, so that they age, reproduce and die. No archetypes means agents of every archetype.
+func WithLifecycle(lifecycle Lifecycle, archetypes ...string) Option {
+	return populate(func(s *Scenario) {
+		for _, agent := range s.state.ofArchetypes(archetypes) {
+			lifecycle := lifecycle
+			agent.Lifecycle = &lifecycle
+		}
+	})
+}
+
+// Add adds agent to the State, assigning it the next ID, and records a Born event. An agent without
+// a source of randomness is given one split from the State's.
+func (s *State) Add(agent *Agent) *Agent {
+	s.ids++
+	agent.ID = s.ids
+	if agent.Random == nil {
+		agent.Random = s.random.Split()
+	}
+	s.Agents = append(s.Agents, agent)
+	s.LifeEvents = append(s.LifeEvents, LifeEvent{Kind: Born, Agent: agent})
+	s.index = nil
+	return agent
+}
+
+// Remove removes agent from the State and records a Died event. Its bonds are broken and every
+// reference other agents hold to it is cleared, so lovers it was courting become single and its
+// subjects are freed.
+func (s *State) Remove(agent *Agent) {
+	remaining := make([]*Agent, 0, len(s.Agents))
+	for _, other := range s.Agents {
+		if other != agent {
+			remaining = append(remaining, other)
+		}
+	}
+	if len(remaining) == len(s.Agents) {
+		return
+	}
+	s.Agents = remaining
+	s.index = nil
+
+	for _, bond := range append([]*Bond{}, s.adjacency[agent]...) {
+		s.BreakBond(bond)
+	}
+	delete(s.adjacency, agent)
+	for _, other := range s.Agents {
+		if lover := other.Lover; lover != nil {
+			if lover.Partner == agent {
+				lover.Partner = nil
+			}
+			if lover.Courting == agent {
+				lover.Courting, lover.Courted, lover.Closeness = nil, 0, 0
+			}
+			if lover.Spurned == agent {
+				lover.Spurned = nil
+			}
+		}
+		if other.Sovereign == agent {
+			other.Sovereign = nil
+		}
+	}
+	s.LifeEvents = append(s.LifeEvents, LifeEvent{Kind: Died, Agent: agent})
+}
+
+// updateLifecycles ages every agent by dt seconds. Agents that outlive their Lifespan die and
+// mature agents with their Reserve of energy give birth with probability Fertility * dt.
+// Offspring are not aged until the next step.
+func (s *State) updateLifecycles(dt float64) {
+	for _, agent := range append([]*Agent{}, s.Agents...) {
+		agent.Age += dt
+		lifecycle := agent.Lifecycle
+		if lifecycle == nil {
+			continue
+		}
+		if lifecycle.Lifespan > 0 && agent.Age >= lifecycle.Lifespan {
+			s.Remove(agent)
+			continue
+		}
+		if agent.Metabolism != nil && agent.Metabolism.Energy < lifecycle.Reserve {
+			continue
+		}
+		if agent.Age >= lifecycle.Maturity && agent.Random.Float64() < lifecycle.Fertility*dt {
+			s.Add(s.offspring(agent))
+		}
+	}
+}
+
+// offspring returns a newborn agent beside parent that inherits its behaviours, archetype,
+// perception, allegiance and half of its energy. The weights of its behaviours, its speed,
+// lifespan and fertility are mutated. A Ruler's offspring establishes a Territory of its own.
+func (s *State) offspring(parent *Agent) *Agent {
+	random := parent.Random.Split()
+	mutated := func(value float64) float64 {
+		return value * math.Max(0, random.Normal(1, parent.Lifecycle.Mutation))
+	}
+
+	child := &Agent{
+		Position:  s.CoordinateSystem.NewVector(parent.Position.X+random.Float(-1, 1), parent.Position.Y+random.Float(-1, 1)),
+		Velocity:  parent.Velocity.Copy(),
+		MaxSpeed:  mutated(parent.MaxSpeed),
+		Heading:   parent.Heading,
+		TurnRate:  parent.TurnRate,
+		Random:    random,
+		Sovereign: parent.Sovereign,
+		Group:     parent.Group,
+	}
+	for _, weighted := range parent.Behaviours {
+		child.Behaviours = append(child.Behaviours, WeightedBehaviour{Behaviour: weighted.Behaviour, Weight: mutated(weighted.Weight)})
+	}
+	lifecycle := *parent.Lifecycle
+	lifecycle.Lifespan, lifecycle.Fertility = mutated(lifecycle.Lifespan), mutated(lifecycle.Fertility)
+	child.Lifecycle = &lifecycle
+
+	if parent.Metabolism != nil {
+		metabolism := *parent.Metabolism
+		metabolism.Energy = parent.Metabolism.Energy / 2
+		parent.Metabolism.Energy -= metabolism.Energy
+		child.Metabolism = &metabolism
+	}
+	if parent.Perception != nil {
+		perception := *parent.Perception
+		child.Perception = &perception
+	}
+	if parent.Body != nil {
+		body := *parent.Body
+		child.Body = &body
+	}
+	if parent.Lover != nil {
+		lover := *parent.Lover
+		lover.Partner, lover.Courting, lover.Spurned, lover.Courted, lover.Closeness = nil, nil, nil, 0, 0
+		lover.MaxSpeed = child.MaxSpeed
+		child.Lover = &lover
+	}
+	if parent.Ruler != nil {
+		ruler := *parent.Ruler
+		s.territories++
+		ruler.Territory = Territory{ID: s.territories, Centre: child.Position.Copy(), Radius: ruler.Establish}
+		ruler.MaxSpeed = child.MaxSpeed
+		child.Ruler = &ruler
+	}
+	if parent.Predator != nil {
+		predator := *parent.Predator
+		predator.MaxSpeed = child.MaxSpeed
+		child.Predator = &predator
+	}
+	if parent.Prey != nil {
+		prey := *parent.Prey
+		prey.MaxSpeed = child.MaxSpeed
+		child.Prey = &prey
+	}
+	return child
+}
+
+// ofArchetypes returns the agents of the given archetypes, or every agent if there are none
+func (s State) ofArchetypes(archetypes []string) []*Agent {
+	if len(archetypes) == 0 {
+		return s.Agents
+	}
+	found := make([]*Agent, 0)
+	for _, agent := range s.Agents {
+		for _, archetype := range archetypes {
+			if agent.Archetype() == archetype {
+				found = append(found, agent)
+				break
+			}
+		}
+	}
+	return found
+}
@@ -0,0 +1,157 @@
+package agents
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestState_Add_NeverReusesIDs(t *testing.T) {
+	scenario := InitialiseScenario(time.Second/60, WithPopulation(3), WithSeed(1))
+	state := scenario.state
+	first := state.Agents[0]
+
+	state.Remove(first)
+	added := state.Add(NewAgent(state.CoordinateSystem, state.Velocities, scenario.random))
+
+	assert.Equal(t, 4, added.ID)
+	ids := map[int]bool{}
+	for _, coords := range state.Frame() {
+		ids[coords.ID] = true
+	}
+	assert.Equal(t, map[int]bool{2: true, 3: true, 4: true}, ids)
+	assert.Equal(t, []LifeEvent{{Kind: Died, Agent: first}, {Kind: Born, Agent: added}}, state.LifeEvents)
+}
+
+func TestState_Add_GivesAgentsRandomness(t *testing.T) {
+	wander := WeightedBehaviour{Behaviour: Wander{Limits{MaxSpeed: 5, MaxForce: 5}, 0.5}, Weight: 1}
+	run := func() *Agent {
+		scenario := InitialiseScenario(time.Second/10, WithPopulation(0), WithSeed(1), WithWorkers(8))
+		var last *Agent
+		for range [16]any{} {
+			last = scenario.state.Add(&Agent{
+				Position:   scenario.positions.NewVector(400, 200),
+				Velocity:   scenario.velocities.ZeroVector(),
+				Behaviours: []WeightedBehaviour{wander},
+			})
+		}
+		for range [10]any{} {
+			scenario.Step()
+		}
+		return last
+	}
+
+	first, second := run(), run()
+	assert.NotNil(t, first.Random)
+	assert.Equal(t, first.Position.X, second.Position.X, "the agents' randomness follows from the Seed")
+	assert.Equal(t, first.Position.Y, second.Position.Y)
+}
+
+func TestState_Remove_LeavesPartnerSingle(t *testing.T) {
+	scenario := couple(50, DefaultLover())
+	a, b := scenario.state.Agents[0], scenario.state.Agents[1]
+	for i := 0; i < 300 && a.Lover.Single(); i++ {
+		scenario.Step()
+	}
+	assert.False(t, a.Lover.Single())
+
+	scenario.state.Remove(a)
+
+	assert.True(t, b.Lover.Single())
+	assert.Empty(t, scenario.state.Bonds)
+	assert.Empty(t, scenario.state.BondsOf(b))
+	assert.Equal(t, []*Agent{b}, scenario.state.Agents)
+}
+
+func TestState_Remove_FreesSubjects(t *testing.T) {
+	scenario := InitialiseScenario(time.Second/60, WithPopulation(1), WithRulers(1, DefaultRuler()), WithSeed(1))
+	subject, sovereign := scenario.state.Agents[0], scenario.state.Agents[1]
+	scenario.state.subjugate(sovereign, subject)
+
+	scenario.state.Remove(sovereign)
+
+	assert.Nil(t, subject.Sovereign)
+	assert.NotPanics(t, scenario.Step)
+}
+
+func TestLifecycle_AgentsDieOfOldAge(t *testing.T) {
+	scenario := InitialiseScenario(time.Second/10, WithPopulation(5), WithLifecycle(Lifecycle{Lifespan: 0.95}), WithSeed(1))
+
+	for range [9]any{} {
+		scenario.Step()
+	}
+	assert.Equal(t, 5, scenario.state.Population())
+
+	scenario.Step()
+	assert.Equal(t, 0, scenario.state.Population())
+	assert.Len(t, scenario.LifeEvents(), 5)
+}
+
+func TestLifecycle_OffspringInheritWithMutation(t *testing.T) {
+	parent := Lifecycle{Maturity: 0.5, Fertility: 10, Mutation: 0.1}
+	scenario := InitialiseScenario(
+		time.Second/10,
+		WithPopulation(1),
+		WithFlocking(DefaultFlocking()),
+		WithLifecycle(parent),
+		WithSeed(1),
+	)
+	ancestor := scenario.state.Agents[0]
+
+	for range [4]any{} {
+		scenario.Step()
+	}
+	assert.Equal(t, 1, scenario.state.Population(), "immature agents do not reproduce")
+	scenario.Step()
+
+	assert.Equal(t, 2, scenario.state.Population())
+	child := scenario.state.Agents[1]
+	assert.Equal(t, []LifeEvent{{Kind: Born, Agent: child}}, scenario.LifeEvents())
+	assert.Equal(t, 2, child.ID)
+	assert.Zero(t, child.Age)
+	assert.Len(t, child.Behaviours, len(ancestor.Behaviours))
+	assert.NotEqual(t, ancestor.MaxSpeed, child.MaxSpeed)
+	assert.InDelta(t, ancestor.MaxSpeed, child.MaxSpeed, ancestor.MaxSpeed/2)
+	assert.NotEqual(t, ancestor.Behaviours[0].Weight, child.Behaviours[0].Weight)
+	assert.Equal(t, parent.Maturity, child.Lifecycle.Maturity)
+	assert.NotEqual(t, parent.Fertility, child.Lifecycle.Fertility)
+}
+
+func TestLifecycle_OffspringOfRulersEstablishTerritories(t *testing.T) {
+	scenario := InitialiseScenario(
+		time.Second/10,
+		WithPopulation(0),
+		WithRulers(1, DefaultRuler()),
+		WithLifecycle(Lifecycle{Fertility: 10}),
+		WithSeed(1),
+	)
+
+	scenario.Step()
+
+	assert.Equal(t, 2, scenario.state.Population())
+	assert.Equal(t, 2, scenario.state.Agents[1].Ruler.Territory.ID)
+}
+
+func TestScenario_Load_ContinuesLifecycle(t *testing.T) {
+	original := InitialiseScenario(
+		time.Second/10,
+		WithPopulation(20),
+		WithLovers(10, DefaultLover()),
+		WithFlocking(DefaultFlocking()),
+		WithLifecycle(Lifecycle{Lifespan: 5, Maturity: 1, Fertility: 0.2, Mutation: 0.1}),
+		WithSeed(3),
+	)
+	for range [40]any{} {
+		original.Step()
+	}
+
+	var saved bytes.Buffer
+	assert.NoError(t, original.Save(&saved))
+	restored, err := Load(&saved)
+	assert.NoError(t, err)
+
+	for range [60]any{} {
+		assert.Equal(t, original.GetNextFrame(), restored.GetNextFrame())
+	}
+}
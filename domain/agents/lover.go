@@ -0,0 +1,163 @@
+package agents
+
+import (
+	"tjweldon/archetypal-agents/domain/world"
+	"tjweldon/archetypal-agents/utils"
+)
+
+// Lover is the archetype of an agent that wants to bond within a context in which bonds are
+// difficult. A Lover without a partner courts the nearest available Lover it perceives, and the
+// pair bond once they have stayed close together for long enough. A Lover that cannot close the
+// distance in time gives up on that candidate and looks for another. If the bond between partners
+// breaks they are both single again.
+type Lover struct {
+	Desire           float64 // Weight of courtship relative to wandering
+	Persistence      float64 // Seconds a candidate is courted before the Lover gives up on them
+	PerceptionRadius float64 // Candidates further away than this are not noticed
+	BondDistance     float64 // Candidates must be within this distance to bond
+	BondTime         float64 // Seconds a candidate must stay within BondDistance to bond
+	Bond             BondSpec
+	MaxSpeed         float64 // The Lover's speed is limited to this
+
+	Partner   *Agent  // The bonded partner, nil while single
+	Courting  *Agent  // The candidate currently being approached
+	Spurned   *Agent  // The last candidate given up on, ignored until someone else is courted
+	Courted   float64 // Seconds spent courting the current candidate
+	Closeness float64 // Seconds the current candidate has continuously been within BondDistance
+}
+
+// DefaultLover returns a Lover that is keen but not especially patient
+func DefaultLover() Lover {
+	return Lover{
+		Desire:           2,
+		Persistence:      10,
+		PerceptionRadius: 100,
+		BondDistance:     10,
+		BondTime:         1,
+		Bond:             BondSpec{RestLength: 8, Stiffness: 1, BreakingStrain: 10},
+		MaxSpeed:         maxSpeed,
+	}
+}
+
+// NewLover initialises an agent with the Lover archetype. It wanders until it notices a candidate
+// and then courts them.
+func NewLover(positions, velocities *world.MetricSpace2D, random *utils.Random, lover Lover) *Agent {
+	agent := NewAgent(positions, velocities, random)
+	agent.Lover = &lover
+	agent.MaxSpeed = lover.MaxSpeed
+	limits := Limits{MaxSpeed: lover.MaxSpeed, MaxForce: lover.MaxSpeed / 2}
+	agent.Behaviours = []WeightedBehaviour{
+		{Behaviour: Courtship{limits}, Weight: lover.Desire},
+		{Behaviour: Wander{limits, 0.5}, Weight: 1},
+	}
+	return agent
+}
+
+// WithLovers adds a group of count agents with the Lover archetype to the Scenario, placed by
+// spawns in turn
+func WithLovers(count int, lover Lover, spawns ...Spawn) Option {
+	return populate(func(s *Scenario) {
+		group := make([]*Agent, count)
+		for index := range group {
+			group[index] = NewLover(s.positions, s.velocities, s.random, lover)
+		}
+		s.spawn(group, spawns)
+	})
+}
+
+// Single reports whether the Lover is available to be courted
+func (l *Lover) Single() bool {
+	return l.Partner == nil
+}
+
+// Courtship steers a Lover towards its partner, or if it has none towards the candidate it is
+// courting. Close to the target the Lover slows so that it stays within BondDistance.
+type Courtship struct {
+	Limits
+}
+
+// Steer implements Behaviour
+func (c Courtship) Steer(self *Agent, view View) world.Vector {
+	lover := self.Lover
+	if lover == nil {
+		return *view.Velocities().ZeroVector()
+	}
+	target := lover.Partner
+	if target == nil {
+		target = lover.Courting
+	}
+	if target == nil {
+		return *view.Velocities().ZeroVector()
+	}
+
+	desired := view.Offset(target.Position)
+	limits := c.Limits
+	if distance := desired.Mag(); distance < lover.BondDistance {
+		limits.MaxSpeed *= distance / lover.BondDistance
+	}
+	return limits.steer(self, desired)
+}
+
+// updateLovers advances the courtship of every single Lover by dt seconds, choosing candidates,
+// forming bonds and giving up on candidates that could not be reached in time. Partners whose bond
+// broke during the step are separated first.
+func (s *State) updateLovers(dt float64) {
+	for _, event := range s.BondEvents {
+		a, b := event.Bond.A, event.Bond.B
+		if event.Kind == BondBroken && a.Lover != nil && a.Lover.Partner == b {
+			a.Lover.Partner, b.Lover.Partner = nil, nil
+		}
+	}
+
+	for index, agent := range s.Agents {
+		lover := agent.Lover
+		if lover == nil || !lover.Single() {
+			continue
+		}
+		if lover.Courting != nil && !lover.Courting.Lover.Single() {
+			lover.Courting = nil
+		}
+		if lover.Courting == nil {
+			lover.Courting = s.nearestCandidate(index)
+			lover.Courted, lover.Closeness = 0, 0
+			if lover.Courting == nil {
+				continue
+			}
+			lover.Spurned = nil
+		}
+
+		lover.Courted += dt
+		if s.CoordinateSystem.Metric(agent.Position, lover.Courting.Position) <= lover.BondDistance {
+			lover.Closeness += dt
+		} else {
+			lover.Closeness = 0
+		}
+
+		switch {
+		case lover.Closeness >= lover.BondTime:
+			partner := lover.Courting
+			lover.Partner, partner.Lover.Partner = partner, agent
+			lover.Courting, partner.Lover.Courting = nil, nil
+			s.FormBond(agent, partner, lover.Bond)
+		case lover.Courted >= lover.Persistence:
+			lover.Spurned, lover.Courting = lover.Courting, nil
+		}
+	}
+}
+
+// nearestCandidate returns the nearest single Lover perceived by State.Agents[index] that it has
+// not just given up on, or nil if there is none
+func (s *State) nearestCandidate(index int) *Agent {
+	lover := s.Agents[index].Lover
+	var nearest *Agent
+	nearestDistance := 0.0
+	for _, other := range s.NewView(index).Neighbours(lover.PerceptionRadius) {
+		if other.Agent.Lover == nil || !other.Agent.Lover.Single() || other.Agent == lover.Spurned {
+			continue
+		}
+		if nearest == nil || other.Distance < nearestDistance {
+			nearest, nearestDistance = other.Agent, other.Distance
+		}
+	}
+	return nearest
+}
@@ -0,0 +1,76 @@
+package agents
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+	"tjweldon/archetypal-agents/domain/world"
+	"tjweldon/archetypal-agents/utils"
+)
+
+// couple places two stationary lovers a given distance apart across the vertical seam
+func couple(separation float64, lover Lover) *Scenario {
+	scenario := InitialiseScenario(time.Second/10, WithLovers(2, lover))
+	scenario.state.Agents = scenario.state.Agents[len(scenario.state.Agents)-2:]
+	for index, agent := range scenario.state.Agents {
+		agent.Position.X, agent.Position.Y = float64(1-2*index)*separation/2, 200
+		agent.Velocity.X, agent.Velocity.Y = 0, 0
+		agent.Behaviours = []WeightedBehaviour{{Behaviour: Courtship{Limits{MaxSpeed: 5, MaxForce: 5}}, Weight: 1}}
+	}
+	return scenario
+}
+
+func TestLover_BondsWhenCloseForLongEnough(t *testing.T) {
+	scenario := couple(50, DefaultLover())
+	a, b := scenario.state.Agents[0], scenario.state.Agents[1]
+
+	for i := 0; i < 300 && a.Lover.Single(); i++ {
+		scenario.Step()
+	}
+
+	assert.Same(t, b, a.Lover.Partner)
+	assert.Same(t, a, b.Lover.Partner)
+	assert.LessOrEqual(t, scenario.state.CoordinateSystem.Metric(a.Position, b.Position), a.Lover.BondDistance)
+}
+
+func TestLover_GivesUpWithoutPersistence(t *testing.T) {
+	lover := DefaultLover()
+	lover.Persistence = 0.5
+	scenario := couple(50, lover)
+	a, b := scenario.state.Agents[0], scenario.state.Agents[1]
+
+	for i := 0; i < 10; i++ {
+		scenario.Step()
+	}
+
+	assert.True(t, a.Lover.Single())
+	assert.Same(t, b, a.Lover.Spurned)
+}
+
+func TestLover_IgnoresCandidatesOutOfSight(t *testing.T) {
+	scenario := couple(300, DefaultLover())
+
+	scenario.Step()
+
+	assert.Nil(t, scenario.state.Agents[0].Lover.Courting)
+}
+
+func TestCourtship_SteersTowardsCandidateAcrossSeam(t *testing.T) {
+	scenario := couple(50, DefaultLover())
+	scenario.Step()
+	a := scenario.state.Agents[0]
+	a.Velocity = world.NewEuclideanPlane().ZeroVector()
+
+	steering := Courtship{Limits{MaxSpeed: 5, MaxForce: 5}}.Steer(a, scenario.state.NewView(0))
+
+	assert.Less(t, steering.X, 0.0)
+}
+
+func TestAgent_Archetype(t *testing.T) {
+	positions, velocities := world.NewEuclideanToroid(width, height), world.NewEuclideanPlane()
+
+	random := utils.NewRandom(1)
+
+	assert.Equal(t, "agent", NewAgent(positions, velocities, random).Archetype())
+	assert.Equal(t, "lover", NewLover(positions, velocities, random, DefaultLover()).Archetype())
+}
@@ -0,0 +1,105 @@
+package agents
+
+import "math"
+
+// Order holds the order parameters of a Scenario after a step, the macroscopic observables whose
+// changes mark its phase transitions, eg. from a disordered swarm to a flock or a mill. They are a
+// part of the socket API (see comment on Coords).
+type Order struct {
+	Time LPFloat `json:"time"` // Seconds
+
+	// Polarization is the magnitude of the mean direction of motion, one when every agent moves the
+	// same way and near zero when they move every which way
+	Polarization LPFloat `json:"polarization"`
+
+	// AngularMomentum is the mean, over the agents, of the angular momentum about the centroid of a
+	// unit mass moving with unit speed at unit distance, one when every agent circles the centroid
+	// anticlockwise, minus one clockwise and near zero when they do not mill
+	AngularMomentum LPFloat `json:"angularMomentum"`
+
+	NearestNeighbour LPFloat `json:"nearestNeighbour"` // The mean distance from an agent to its nearest neighbour
+	Clusters         int     `json:"clusters"`         // The number of clusters, see Clustering
+	Bonds            int     `json:"bonds"`
+}
+
+// Order measures the order parameters of the Scenario as it is now. Stationary agents have no
+// direction of motion and count towards neither the Polarization nor the AngularMomentum. If the
+// Scenario has a Clustering the clusters are the groups it last found, otherwise they are found
+// with the DefaultClustering.
+func (s *Scenario) Order() Order {
+	state := s.state
+	order := Order{
+		Time:             LPFloat{Value: s.Time.Seconds(), Digits: 3},
+		Polarization:     LPFloat{Digits: 3},
+		AngularMomentum:  LPFloat{Digits: 3},
+		NearestNeighbour: LPFloat{Digits: 2},
+		Bonds:            len(state.Bonds),
+	}
+	if state.Population() == 0 {
+		return order
+	}
+
+	centroid := state.CoordinateSystem.Centroid(state.positionsOf())
+	headingX, headingY, milling, moving := 0.0, 0.0, 0.0, 0
+	for _, agent := range state.Agents {
+		speed := math.Hypot(agent.Velocity.X, agent.Velocity.Y)
+		if speed == 0 {
+			continue
+		}
+		moving++
+		unitX, unitY := agent.Velocity.X/speed, agent.Velocity.Y/speed
+		headingX, headingY = headingX+unitX, headingY+unitY
+
+		offsetX, offsetY := state.CoordinateSystem.GeodesicDiff(centroid.X, agent.Position.X, centroid.Y, agent.Position.Y)
+		if distance := math.Hypot(offsetX, offsetY); distance > 0 {
+			milling += (offsetX*unitY - offsetY*unitX) / distance
+		}
+	}
+	if moving > 0 {
+		order.Polarization.Value = math.Hypot(headingX, headingY) / float64(moving)
+		order.AngularMomentum.Value = milling / float64(moving)
+	}
+
+	nearest := state.nearestNeighbours()
+	total := 0.0
+	for _, distance := range nearest {
+		total += distance
+	}
+	if len(nearest) > 0 {
+		order.NearestNeighbour.Value = total / float64(len(nearest))
+	}
+
+	if state.Clustering != nil {
+		order.Clusters = state.groupCount()
+	} else {
+		clustering := DefaultClustering()
+		order.Clusters = len(state.Clusters(clustering.Radius, clustering.MinPoints))
+	}
+	return order
+}
+
+// nearestNeighbours returns the distance from each agent to its nearest neighbour, or nothing if
+// there are too few agents for any to have one
+func (s *State) nearestNeighbours() []float64 {
+	if s.Population() < 2 {
+		return nil
+	}
+	s.reindex()
+	distances := make([]float64, s.Population())
+	s.ForEach(func(index int) {
+		_, distances[index] = s.index.Nearest(index)
+	})
+	return distances
+}
+
+// groupCount returns the number of distinct groups the agents are in, agents in group zero are in
+// none
+func (s *State) groupCount() int {
+	groups := make(map[int]bool)
+	for _, agent := range s.Agents {
+		if agent.Group != 0 {
+			groups[agent.Group] = true
+		}
+	}
+	return len(groups)
+}
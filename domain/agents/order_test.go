@@ -0,0 +1,78 @@
+package agents
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+	"tjweldon/archetypal-agents/domain/world"
+)
+
+// mill places twelve agents evenly around a circle of radius 50 centred on the seam of the default
+// toroid, moving anticlockwise around it at speed 5
+func mill(options ...Option) *Scenario {
+	scenario := InitialiseScenario(time.Second/10, append([]Option{WithPopulation(12)}, options...)...)
+	for index, agent := range scenario.state.Agents {
+		angle := 2 * math.Pi * float64(index) / 12
+		agent.Position.X, agent.Position.Y = scenario.positions.XCoord.Wrap(50*math.Cos(angle)), 200+50*math.Sin(angle)
+		agent.Velocity.X, agent.Velocity.Y = -5*math.Sin(angle), 5*math.Cos(angle)
+	}
+	return scenario
+}
+
+func TestScenario_Order_Milling(t *testing.T) {
+	order := mill().Order()
+
+	assert.InDelta(t, 0.0, order.Polarization.Value, MaxPrecision)
+	assert.InDelta(t, 1.0, order.AngularMomentum.Value, MaxPrecision, "the centroid is found across the seam")
+	assert.InDelta(t, 100*math.Sin(math.Pi/12), order.NearestNeighbour.Value, MaxPrecision)
+}
+
+func TestScenario_Order_Polarized(t *testing.T) {
+	scenario := mill()
+	for _, agent := range scenario.state.Agents {
+		agent.Velocity.X, agent.Velocity.Y = 0, -3
+	}
+	scenario.state.Agents[0].Velocity.Y, scenario.state.Agents[6].Velocity.Y = 0, 0
+
+	order := scenario.Order()
+
+	assert.InDelta(t, 1.0, order.Polarization.Value, MaxPrecision, "stationary agents do not count")
+	assert.InDelta(t, 0.0, order.AngularMomentum.Value, MaxPrecision)
+}
+
+func TestScenario_Order_ClustersAndBonds(t *testing.T) {
+	scenario := mill(WithClustering(Clustering{Radius: 30, MinPoints: 3}))
+	scenario.state.regroup()
+	scenario.state.FormBond(scenario.state.Agents[0], scenario.state.Agents[1], BondSpec{Stiffness: 1})
+
+	order := scenario.Order()
+
+	assert.Equal(t, 1, order.Clusters)
+	assert.Equal(t, 1, order.Bonds)
+	scenario.state.Agents[0].Group = 7
+	assert.Equal(t, 2, scenario.Order().Clusters, "the clusters are the groups the Clustering found")
+	assert.Zero(t, mill().Order().Clusters, "the agents are too far apart for the default clustering")
+}
+
+func TestState_nearestNeighbours_IsolatedAgents(t *testing.T) {
+	scenario := InitialiseScenario(time.Second/10, WithPopulation(2), WithWorld(1000, 1000, world.NewEuclideanPlane()))
+	scenario.state.Agents[0].Position.X, scenario.state.Agents[0].Position.Y = 0, 0
+	scenario.state.Agents[1].Position.X, scenario.state.Agents[1].Position.Y = 3000, 4000
+
+	assert.Equal(t, []float64{5000, 5000}, scenario.state.nearestNeighbours())
+}
+
+// BenchmarkScenario_Order_10k measures the crowd of BenchmarkScenario_Step_10k grouped by the
+// DefaultClustering, as the headline scenario is
+func BenchmarkScenario_Order_10k(b *testing.B) {
+	scenario := crowd(10000, 10)
+	clustering := DefaultClustering()
+	scenario.state.Clustering = &clustering
+	scenario.state.regroup()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		scenario.Order()
+	}
+}
@@ -0,0 +1,89 @@
+package agents
+
+import (
+	"runtime"
+	"sync"
+)
+
+// pool is a fixed set of goroutines that the work of a step is spread over. The goroutines are
+// started with the pool and stop once it is garbage collected, so stepping does not start any.
+type pool struct {
+	workers int
+	blocks  chan block // Nil if the work is done on the calling goroutine
+}
+
+// block is a contiguous range of indices to call work for, done is notified once they all have
+// been
+type block struct {
+	start, end int
+	work       func(index int)
+	done       *sync.WaitGroup
+}
+
+// newPool starts a pool of workers goroutines, a pool of one worker or fewer uses the calling
+// goroutine instead
+func newPool(workers int) *pool {
+	p := &pool{workers: workers}
+	if workers <= 1 {
+		return p
+	}
+	// The goroutines only hold the channel, so the pool can be collected while they wait on it
+	blocks := make(chan block)
+	for worker := 0; worker < workers; worker++ {
+		go serve(blocks)
+	}
+	p.blocks = blocks
+	runtime.SetFinalizer(p, func(p *pool) {
+		close(p.blocks)
+	})
+	return p
+}
+
+// serve does the work of blocks until the channel is closed
+func serve(blocks <-chan block) {
+	for block := range blocks {
+		for index := block.start; index < block.end; index++ {
+			block.work(index)
+		}
+		block.done.Done()
+	}
+}
+
+// forEach calls work for every index in [0, count) on the goroutines of the pool, returning once
+// all the calls have completed. Each goroutine handles a contiguous block of indices. The calls run
+// concurrently, so work may read shared data but must only write to data owned by its index, and
+// must not call forEach itself. A nil pool calls work on the calling goroutine.
+func (p *pool) forEach(count int, work func(index int)) {
+	workers := 1
+	if p != nil && p.blocks != nil {
+		workers = p.workers
+	}
+	if workers > count {
+		workers = count
+	}
+	if workers <= 1 {
+		for index := 0; index < count; index++ {
+			work(index)
+		}
+		return
+	}
+
+	var done sync.WaitGroup
+	size := (count + workers - 1) / workers
+	for start := 0; start < count; start += size {
+		end := start + size
+		if end > count {
+			end = count
+		}
+		done.Add(1)
+		p.blocks <- block{start: start, end: end, work: work, done: &done}
+	}
+	done.Wait()
+}
+
+// ForEach calls work for the index into State.Agents of every agent, spread over the workers of
+// the Scenario (see WithWorkers). The calls run concurrently, so work may read the State but must
+// only write to the agent at its index. Integrators use it to update the agents in parallel.
+func (s *State) ForEach(work func(index int)) {
+	s.pool.forEach(s.Population(), work)
+}
@@ -0,0 +1,67 @@
+package agents
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPool_forEach_VisitsEveryIndexOnce(t *testing.T) {
+	for _, workers := range []int{0, 1, 3, 8, 200} {
+		pool := newPool(workers)
+		for range [3]any{} {
+			visits := make([]int32, 100)
+			pool.forEach(len(visits), func(index int) {
+				atomic.AddInt32(&visits[index], 1)
+			})
+			for index, count := range visits {
+				assert.Equal(t, int32(1), count, "index %d with %d workers", index, workers)
+			}
+		}
+	}
+}
+
+// twin returns a copy of the scenario with its own copies of every agent and random source. The
+// archetypes of the agents are shared between the copies, so the scenario should not have any.
+func twin(scenario *Scenario, workers int) *Scenario {
+	copied := *scenario
+	copied.state = scenario.state.Clone()
+	for _, agent := range copied.state.Agents {
+		agent.Random = agent.Random.Copy()
+	}
+	copied.workers, copied.state.pool = workers, newPool(workers)
+	return &copied
+}
+
+func TestScenario_Step_ParallelIsBitIdentical(t *testing.T) {
+	wander := WeightedBehaviour{Behaviour: Wander{Limits{MaxSpeed: maxSpeed, MaxForce: maxSpeed}, 0.5}, Weight: 1}
+	for name, integrator := range integrators {
+		serial := InitialiseScenario(
+			time.Second/60,
+			WithIntegrator(integrator),
+			WithFlocking(DefaultFlocking()),
+			WithWorkers(1),
+		)
+		for index, agent := range serial.state.Agents {
+			agent.Behaviours = append(agent.Behaviours, wander)
+			if index%2 == 1 {
+				serial.state.FormBond(serial.state.Agents[index-1], agent, DefaultLover().Bond)
+			}
+		}
+		parallel := twin(serial, 8)
+
+		for i := 0; i < 50; i++ {
+			serial.Step()
+			parallel.Step()
+		}
+
+		for index, agent := range serial.state.Agents {
+			other := parallel.state.Agents[index]
+			assert.Equal(t, agent.Position.X, other.Position.X, name)
+			assert.Equal(t, agent.Position.Y, other.Position.Y, name)
+			assert.Equal(t, agent.Velocity.X, other.Velocity.X, name)
+			assert.Equal(t, agent.Velocity.Y, other.Velocity.Y, name)
+		}
+	}
+}
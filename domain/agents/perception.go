@@ -0,0 +1,70 @@
+package agents
+
+import (
+	"math"
+	"tjweldon/archetypal-agents/domain/world"
+)
+
+// Perception is an agent's sensing model. An agent perceives what is within its Range, inside the
+// cone of its FieldOfView about its Heading, and if it is subject to Occlusion not hidden behind a
+// wall of the Maze. Behaviours only learn of the agents and patches their agent perceives.
+type Perception struct {
+	Range       float64 // The furthest the agent can sense, zero is unlimited
+	FieldOfView float64 // Full angle of the cone about the heading in radians, zero or 2π and above see all round
+	Occlusion   bool    // Walls of the Maze hide what is behind them
+}
+
+// DefaultPerception returns a Perception that sees a little further than the flocking radius,
+// through a wide cone with a blind spot behind, and not through walls
+func DefaultPerception() Perception {
+	return Perception{Range: 80, FieldOfView: 270 * math.Pi / 180, Occlusion: true}
+}
+
+// WithPerception gives every agent of the given archetypes added to the Scenario before it a
+// Perception. No archetypes means agents of every archetype, agents without one sense everything
+// their behaviours ask about.
+func WithPerception(perception Perception, archetypes ...string) Option {
+	return populate(func(s *Scenario) {
+		for _, agent := range s.state.ofArchetypes(archetypes) {
+			perception := perception
+			agent.Perception = &perception
+		}
+	})
+}
+
+// within reports whether a displacement of offset from an agent facing heading is inside the
+// field of view
+func (p Perception) within(heading float64, offset world.Vector) bool {
+	if p.FieldOfView <= 0 || p.FieldOfView >= 2*math.Pi || (offset.X == 0 && offset.Y == 0) {
+		return true
+	}
+	bearing := math.Remainder(math.Atan2(offset.Y, offset.X)-heading, 2*math.Pi)
+	return math.Abs(bearing) <= p.FieldOfView/2
+}
+
+// perceives reports whether the viewing agent senses something at a geodesic displacement of
+// offset from it
+func (v View) perceives(offset world.Vector) bool {
+	self := v.state.Agents[v.index]
+	perception := self.Perception
+	if perception == nil {
+		return true
+	}
+	if perception.Range > 0 && math.Hypot(offset.X, offset.Y) >= perception.Range {
+		return false
+	}
+	if !perception.within(self.Heading, offset) {
+		return false
+	}
+	if perception.Occlusion && v.state.Maze != nil {
+		if _, _, hidden := v.state.Maze.Crossing(self.Position, offset.X, offset.Y); hidden {
+			return false
+		}
+	}
+	return true
+}
+
+// Perceives reports whether the viewing agent senses a point in the position space
+func (v View) Perceives(position *world.Vector) bool {
+	return v.perceives(v.Offset(position))
+}
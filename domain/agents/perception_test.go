@@ -0,0 +1,103 @@
+package agents
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"tjweldon/archetypal-agents/domain/world"
+	"tjweldon/archetypal-agents/worlds"
+)
+
+// lookout places an agent near the left seam of an 800x400 toroid heading in the +x direction,
+// with a Perception, and stationary agents ahead, behind, beside and far ahead of it. The agent
+// behind is across the seam.
+func lookout(perception Perception) *State {
+	toroid, plane := world.NewEuclideanToroid(width, height), world.NewEuclideanPlane()
+	agent := func(x, y float64) *Agent {
+		return &Agent{Position: toroid.NewVector(x, y), Velocity: plane.NewVector(0, 0)}
+	}
+	state := &State{
+		CoordinateSystem: toroid,
+		Velocities:       plane,
+		Agents:           []*Agent{agent(5, 200), agent(15, 200), agent(width-5, 200), agent(5, 210), agent(45, 200)},
+	}
+	state.Agents[0].Velocity.X = 3
+	state.Agents[0].Perception = &perception
+	return state
+}
+
+// perceived returns the indices into State.Agents of the neighbours
+func perceived(state *State, neighbours []Neighbour) []int {
+	indices := make([]int, 0)
+	for _, neighbour := range neighbours {
+		for index, agent := range state.Agents {
+			if agent == neighbour.Agent {
+				indices = append(indices, index)
+			}
+		}
+	}
+	return indices
+}
+
+func TestView_Neighbours_FieldOfView(t *testing.T) {
+	state := lookout(Perception{FieldOfView: math.Pi / 2})
+
+	assert.Equal(t, []int{1, 4}, perceived(state, state.NewView(0).Neighbours(50)))
+
+	state.Agents[0].Perception.FieldOfView = math.Pi + 0.01
+	assert.Equal(t, []int{1, 3, 4}, perceived(state, state.NewView(0).Neighbours(50)))
+}
+
+func TestView_Neighbours_FollowsHeading(t *testing.T) {
+	state := lookout(Perception{FieldOfView: math.Pi / 2})
+	state.Agents[0].Velocity.X, state.Agents[0].Heading = 0, math.Pi
+
+	assert.Equal(t, []int{2}, perceived(state, state.NewView(0).Neighbours(50)), "a stationary agent looks where it faces")
+}
+
+func TestView_Neighbours_Range(t *testing.T) {
+	state := lookout(Perception{Range: 20})
+
+	assert.Equal(t, []int{1, 2, 3}, perceived(state, state.NewView(0).Neighbours(50)))
+}
+
+func TestView_Neighbours_Occlusion(t *testing.T) {
+	state := lookout(Perception{Occlusion: true})
+	// A wall along the seam hides the agent behind, and one ahead hides the far agent
+	state.Maze = worlds.NewMaze(state.CoordinateSystem, worlds.Wall{X1: 0, Y1: 150, X2: 0, Y2: 250}, worlds.Wall{X1: 30, Y1: 150, X2: 30, Y2: 250})
+
+	assert.Equal(t, []int{1, 3}, perceived(state, state.NewView(0).Neighbours(50)))
+
+	state.Agents[0].Perception.Occlusion = false
+	assert.Equal(t, []int{1, 2, 3, 4}, perceived(state, state.NewView(0).Neighbours(50)))
+}
+
+func TestSeparation_IgnoresUnperceivedNeighbours(t *testing.T) {
+	state := lookout(Perception{FieldOfView: math.Pi / 2})
+	state.Agents = state.Agents[:3]
+	state.Agents[1].Position.X = 7
+	separation := Separation{Limits{MaxSpeed: 10, MaxForce: 1000}, 5}
+
+	ahead := separation.Steer(state.Agents[0], state.NewView(0))
+	assert.Less(t, ahead.X, 0.0, "the agent keeps away from the agent ahead")
+
+	state.Agents[0].Velocity.X, state.Agents[0].Heading = -3, math.Pi
+	behind := separation.Steer(state.Agents[0], state.NewView(0))
+	assert.Zero(t, behind.Mag(), "the agent behind it is out of sight")
+}
+
+func TestView_Patches_Perceived(t *testing.T) {
+	state := lookout(Perception{Range: 20, FieldOfView: math.Pi / 2})
+	state.Resources = worlds.NewResources(state.CoordinateSystem,
+		worlds.Patch{X: 15, Y: 200, Radius: 2},  // Ahead
+		worlds.Patch{X: 30, Y: 200, Radius: 2},  // Out of range
+		worlds.Patch{X: 0, Y: 200, Radius: 10},  // Behind, but the agent is in it
+		worlds.Patch{X: 795, Y: 200, Radius: 2}, // Behind
+	)
+
+	patches := state.NewView(0).Patches()
+
+	assert.Len(t, patches, 2)
+	assert.Equal(t, 15.0, patches[0].X)
+	assert.Equal(t, 0.0, patches[1].X)
+}
@@ -0,0 +1,190 @@
+package agents
+
+import (
+	"math"
+	"tjweldon/archetypal-agents/domain/world"
+	"tjweldon/archetypal-agents/utils"
+)
+
+// Predator is the archetype of an agent that hunts Prey. It pursues the nearest Prey it perceives,
+// aiming at where the prey is heading rather than where it is, and captures prey within Reach. A
+// captured prey is removed and the Predator eats it, gaining Nourishment if it has a Metabolism.
+type Predator struct {
+	PerceptionRadius float64 // Prey further away than this are not noticed
+	Reach            float64 // Distance within which prey are captured
+	Nourishment      float64 // Energy gained from each capture
+	Lead             float64 // The furthest ahead in seconds a pursuit aims, zero pursues the prey directly
+	MaxSpeed         float64 // The Predator's speed is limited to this
+}
+
+// DefaultPredator returns a Predator that is a little faster than its prey
+func DefaultPredator() Predator {
+	return Predator{
+		PerceptionRadius: 120,
+		Reach:            6,
+		Nourishment:      40,
+		Lead:             2,
+		MaxSpeed:         1.1 * maxSpeed,
+	}
+}
+
+// Prey is the archetype of an agent that is hunted by Predators. It flocks with other Prey and
+// flees from the Predators it perceives.
+type Prey struct {
+	PerceptionRadius float64 // Predators further away than this are not noticed
+	Fear             float64 // Weight of evasion relative to flocking
+	MaxSpeed         float64 // The Prey's speed is limited to this
+}
+
+// DefaultPrey returns a Prey that puts escaping before staying with the flock
+func DefaultPrey() Prey {
+	return Prey{PerceptionRadius: 80, Fear: 3, MaxSpeed: maxSpeed}
+}
+
+// NewPredator initialises an agent with the Predator archetype. It wanders until it notices prey
+// and then pursues them.
+func NewPredator(positions, velocities *world.MetricSpace2D, random *utils.Random, predator Predator) *Agent {
+	agent := NewAgent(positions, velocities, random)
+	agent.Predator = &predator
+	agent.MaxSpeed = predator.MaxSpeed
+	limits := Limits{MaxSpeed: predator.MaxSpeed, MaxForce: predator.MaxSpeed}
+	agent.Behaviours = []WeightedBehaviour{
+		{Behaviour: Pursuit{limits}, Weight: 2},
+		{Behaviour: Wander{limits, 0.5}, Weight: 0.5},
+	}
+	return agent
+}
+
+// NewPrey initialises an agent with the Prey archetype. It flocks with other prey using the
+// default flocking radius, and evades predators.
+func NewPrey(positions, velocities *world.MetricSpace2D, random *utils.Random, prey Prey) *Agent {
+	agent := NewAgent(positions, velocities, random)
+	agent.Prey = &prey
+	agent.MaxSpeed = prey.MaxSpeed
+	limits := Limits{MaxSpeed: prey.MaxSpeed, MaxForce: prey.MaxSpeed / 2}
+	radius := DefaultFlocking().PerceptionRadius
+	agent.Behaviours = []WeightedBehaviour{
+		{Behaviour: Evade{Limits{MaxSpeed: prey.MaxSpeed, MaxForce: prey.MaxSpeed}}, Weight: prey.Fear},
+		{Behaviour: Separation{limits, radius}, Weight: 1},
+		{Behaviour: Cohesion{limits, radius, true}, Weight: 1},
+		{Behaviour: Alignment{limits, radius, true}, Weight: 1},
+	}
+	return agent
+}
+
+// WithPredators adds a group of count agents with the Predator archetype to the Scenario, placed by
+// spawns in turn
+func WithPredators(count int, predator Predator, spawns ...Spawn) Option {
+	return populate(func(s *Scenario) {
+		group := make([]*Agent, count)
+		for index := range group {
+			group[index] = NewPredator(s.positions, s.velocities, s.random, predator)
+		}
+		s.spawn(group, spawns)
+	})
+}
+
+// WithPrey adds a group of count agents with the Prey archetype to the Scenario, placed by spawns
+// in turn
+func WithPrey(count int, prey Prey, spawns ...Spawn) Option {
+	return populate(func(s *Scenario) {
+		group := make([]*Agent, count)
+		for index := range group {
+			group[index] = NewPrey(s.positions, s.velocities, s.random, prey)
+		}
+		s.spawn(group, spawns)
+	})
+}
+
+// Pursuit steers a Predator towards the point the nearest prey it perceives will reach if it keeps
+// its velocity, looking ahead by the time the Predator would take to cover the distance at full
+// speed, up to Lead seconds. The displacement is geodesic so prey are pursued across seams.
+type Pursuit struct {
+	Limits
+}
+
+// Steer implements Behaviour
+func (p Pursuit) Steer(self *Agent, view View) world.Vector {
+	predator := self.Predator
+	if predator == nil {
+		return *view.Velocities().ZeroVector()
+	}
+
+	var nearest *Neighbour
+	for _, other := range view.Neighbours(predator.PerceptionRadius) {
+		other := other
+		if other.Agent.Prey != nil && (nearest == nil || other.Distance < nearest.Distance) {
+			nearest = &other
+		}
+	}
+	if nearest == nil {
+		return *view.Velocities().ZeroVector()
+	}
+
+	lead := predator.Lead
+	if p.MaxSpeed > 0 {
+		lead = math.Min(lead, nearest.Distance/p.MaxSpeed)
+	}
+	ahead := nearest.Agent.Velocity.Times(lead)
+	target := nearest.Offset
+	return p.steer(self, *target.Accumulate(&target, &ahead))
+}
+
+// Evade steers Prey away from the Predators it perceives, weighting each by the inverse of its
+// distance so that the nearest threat dominates
+type Evade struct {
+	Limits
+}
+
+// Steer implements Behaviour
+func (e Evade) Steer(self *Agent, view View) world.Vector {
+	desired := view.Velocities().ZeroVector()
+	prey := self.Prey
+	if prey == nil {
+		return *desired
+	}
+	threats := 0
+	for _, other := range view.Neighbours(prey.PerceptionRadius) {
+		if other.Agent.Predator == nil || other.Distance == 0 {
+			continue
+		}
+		away := other.Offset.Times(-1 / (other.Distance * other.Distance))
+		desired.Accumulate(desired, &away)
+		threats++
+	}
+	if threats == 0 {
+		return *desired
+	}
+	return e.steer(self, *desired)
+}
+
+// updatePredators lets every Predator capture the nearest prey within its Reach, which is removed
+// from the State and eaten
+func (s *State) updatePredators() {
+	for _, agent := range append([]*Agent{}, s.Agents...) {
+		predator := agent.Predator
+		if predator == nil || agent.Dormant() {
+			continue
+		}
+
+		var caught *Agent
+		nearest := math.Inf(1)
+		for _, candidate := range s.WithinRadius(agent.Position, predator.Reach) {
+			other := s.Agents[candidate]
+			if other.Prey == nil {
+				continue
+			}
+			if distance := s.CoordinateSystem.Metric(agent.Position, other.Position); distance < nearest {
+				caught, nearest = other, distance
+			}
+		}
+		if caught == nil {
+			continue
+		}
+
+		s.Remove(caught)
+		if metabolism := agent.Metabolism; metabolism != nil {
+			metabolism.Energy = math.Min(metabolism.Capacity, metabolism.Energy+predator.Nourishment)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package agents
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// hunt places a stationary Predator at (100, 200) and a Prey at (x, y) moving with (u, v)
+func hunt(x, y, u, v float64) (*Scenario, *Agent, *Agent) {
+	scenario := InitialiseScenario(
+		time.Second/10,
+		WithPopulation(0),
+		WithPredators(1, DefaultPredator()),
+		WithPrey(1, DefaultPrey()),
+		WithSeed(1),
+	)
+	hunter, quarry := scenario.state.Agents[0], scenario.state.Agents[1]
+	hunter.Position.X, hunter.Position.Y = 100, 200
+	hunter.Velocity.X, hunter.Velocity.Y = 0, 0
+	quarry.Position.X, quarry.Position.Y = x, y
+	quarry.Velocity.X, quarry.Velocity.Y = u, v
+	return scenario, hunter, quarry
+}
+
+func TestPursuit_LeadsPreyAcrossSeam(t *testing.T) {
+	scenario, hunter, _ := hunt(100-60+width, 200, 0, 10)
+	pursuit := Pursuit{Limits{MaxSpeed: 30, MaxForce: 1000}}
+
+	steering := pursuit.Steer(hunter, scenario.state.NewView(0))
+
+	// 60 away at 30 per second is 2 seconds, in which the prey moves 20 down
+	assert.InDelta(t, -20.0/60, steering.Y/steering.X, MaxPrecision)
+	assert.Less(t, steering.X, 0.0)
+}
+
+func TestPursuit_LooksAheadNoFurtherThanLead(t *testing.T) {
+	scenario, hunter, _ := hunt(200, 200, 0, 10)
+	hunter.Predator.Lead = 0.5
+	pursuit := Pursuit{Limits{MaxSpeed: 10, MaxForce: 1000}}
+
+	steering := pursuit.Steer(hunter, scenario.state.NewView(0))
+
+	assert.InDelta(t, 5.0/100, steering.Y/steering.X, MaxPrecision)
+}
+
+func TestEvade_FleesPredators(t *testing.T) {
+	scenario, _, quarry := hunt(130, 200, 0, 0)
+	evade := Evade{Limits{MaxSpeed: 10, MaxForce: 1000}}
+
+	steering := evade.Steer(quarry, scenario.state.NewView(1))
+
+	assert.InDelta(t, 10.0, steering.X, MaxPrecision)
+	assert.InDelta(t, 0.0, steering.Y, MaxPrecision)
+}
+
+func TestCohesion_KinIgnoresOtherArchetypes(t *testing.T) {
+	scenario, _, quarry := hunt(130, 200, 0, 0)
+	cohesion := Cohesion{Limits{MaxSpeed: 10, MaxForce: 1000}, 50, true}
+
+	steering := cohesion.Steer(quarry, scenario.state.NewView(1))
+
+	assert.Zero(t, steering.Mag())
+}
+
+func TestPredator_CapturesAndEatsPrey(t *testing.T) {
+	scenario, hunter, quarry := hunt(104, 200, 0, 0)
+	hunter.Metabolism = &Metabolism{Energy: 10, Capacity: 100}
+
+	scenario.Step()
+
+	assert.Equal(t, []*Agent{hunter}, scenario.state.Agents)
+	assert.Equal(t, []LifeEvent{{Kind: Died, Agent: quarry}}, scenario.LifeEvents())
+	assert.InDelta(t, 10+DefaultPredator().Nourishment, hunter.Metabolism.Energy, MaxPrecision)
+}
+
+func TestLifecycle_ReproductionNeedsReserve(t *testing.T) {
+	scenario, hunter, _ := hunt(300, 200, 0, 0)
+	hunter.Metabolism = &Metabolism{Energy: 10, Capacity: 100}
+	hunter.Lifecycle = &Lifecycle{Fertility: 10, Reserve: 20}
+	hunter.Behaviours = nil
+
+	scenario.Step()
+	assert.Equal(t, 2, scenario.state.Population())
+
+	hunter.Metabolism.Energy = 30
+	scenario.Step()
+	assert.Equal(t, 3, scenario.state.Population())
+	assert.Equal(t, "predator", scenario.state.Agents[2].Archetype())
+	assert.InDelta(t, 15.0, hunter.Metabolism.Energy, MaxPrecision)
+}
+
+func TestScenario_Stats_CountsArchetypesAndEvents(t *testing.T) {
+	scenario, hunter, _ := hunt(104, 200, 0, 0)
+	scenario.state.Add(NewLover(scenario.positions, scenario.velocities, scenario.random, DefaultLover()))
+	hunter.Behaviours = nil
+
+	scenario.Step()
+	stats := scenario.Stats()
+
+	assert.Equal(t, 0.1, stats.Time.Value)
+	assert.Equal(t, 2, stats.Population)
+	assert.Equal(t, map[string]int{"predator": 1, "lover": 1}, stats.Archetypes)
+	assert.Equal(t, 0, stats.Births)
+	assert.Equal(t, 1, stats.Deaths)
+}
+
+func TestWithMetabolism_OnlyGivenArchetypes(t *testing.T) {
+	scenario := InitialiseScenario(
+		time.Second/10,
+		WithPopulation(0),
+		WithPredators(2, DefaultPredator()),
+		WithPrey(3, DefaultPrey()),
+		WithMetabolism(DefaultMetabolism(), "predator"),
+	)
+
+	for _, agent := range scenario.state.Agents {
+		assert.Equal(t, agent.Predator != nil, agent.Metabolism != nil)
+	}
+}
+
+func TestScenario_Load_ContinuesPredation(t *testing.T) {
+	original := InitialiseScenario(
+		time.Second/10,
+		WithPopulation(0),
+		WithPredators(3, DefaultPredator()),
+		WithPrey(20, DefaultPrey(), Clusters{Count: 2, Spread: 20}),
+		WithMetabolism(DefaultMetabolism(), "predator"),
+		WithLifecycle(Lifecycle{Maturity: 1, Fertility: 0.2, Mutation: 0.1, Reserve: 50}),
+		WithSeed(3),
+	)
+	for range [40]any{} {
+		original.Step()
+	}
+
+	var saved bytes.Buffer
+	assert.NoError(t, original.Save(&saved))
+	restored, err := Load(&saved)
+	assert.NoError(t, err)
+
+	for range [60]any{} {
+		assert.Equal(t, original.GetNextFrame(), restored.GetNextFrame())
+		assert.Equal(t, original.Stats(), restored.Stats())
+	}
+}
@@ -0,0 +1,86 @@
+package agents
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// registry names the concrete types of an interface so that values can be recorded and rebuilt
+// from their name and fields
+type registry struct {
+	names map[reflect.Type]string
+	types map[string]reflect.Type
+}
+
+func newRegistry() *registry {
+	return &registry{names: map[reflect.Type]string{}, types: map[string]reflect.Type{}}
+}
+
+// register names the concrete type of prototype
+func (r *registry) register(name string, prototype any) {
+	r.names[reflect.TypeOf(prototype)] = name
+	r.types[name] = reflect.TypeOf(prototype)
+}
+
+// name returns the name the concrete type of value is registered under
+func (r *registry) name(value any) (string, error) {
+	name, ok := r.names[reflect.TypeOf(value)]
+	if !ok {
+		return "", fmt.Errorf("%T is not registered", value)
+	}
+	return name, nil
+}
+
+// build decodes fields into a new value of the type registered under name. Missing fields are
+// left at their zero values.
+func (r *registry) build(name string, fields json.RawMessage) (any, error) {
+	concrete, ok := r.types[name]
+	if !ok {
+		return nil, fmt.Errorf("%q is not registered", name)
+	}
+	value := reflect.New(concrete)
+	if len(fields) > 0 {
+		if err := json.Unmarshal(fields, value.Interface()); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return value.Elem().Interface(), nil
+}
+
+var (
+	behaviourTypes  = newRegistry()
+	integratorTypes = newRegistry()
+)
+
+func init() {
+	RegisterBehaviour("seek", Seek{})
+	RegisterBehaviour("flee", Flee{})
+	RegisterBehaviour("wander", Wander{})
+	RegisterBehaviour("separation", Separation{})
+	RegisterBehaviour("cohesion", Cohesion{})
+	RegisterBehaviour("alignment", Alignment{})
+	RegisterBehaviour("courtship", Courtship{})
+	RegisterBehaviour("reign", Reign{})
+	RegisterBehaviour("allegiance", Allegiance{})
+	RegisterBehaviour("forage", Forage{})
+	RegisterBehaviour("pursuit", Pursuit{})
+	RegisterBehaviour("evade", Evade{})
+
+	RegisterIntegrator("explicit-euler", ExplicitEuler{})
+	RegisterIntegrator("semi-implicit-euler", SemiImplicitEuler{})
+	RegisterIntegrator("velocity-verlet", VelocityVerlet{})
+	RegisterIntegrator("runge-kutta-4", RungeKutta4{})
+}
+
+// RegisterBehaviour names the type of prototype so that agents with Behaviours of that type can be
+// saved in a Snapshot. The Behaviour is recorded by its exported fields.
+func RegisterBehaviour(name string, prototype Behaviour) {
+	behaviourTypes.register(name, prototype)
+}
+
+// RegisterIntegrator names the type of prototype so that Scenarios using it can be saved in a
+// Snapshot
+func RegisterIntegrator(name string, prototype Integrator) {
+	integratorTypes.register(name, prototype)
+}
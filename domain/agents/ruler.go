@@ -0,0 +1,232 @@
+package agents
+
+import (
+	"math"
+	"tjweldon/archetypal-agents/domain/world"
+	"tjweldon/archetypal-agents/utils"
+)
+
+// Territory is a disc in the position space claimed by a Ruler. It is identified by an ID that is
+// unique within the Scenario, zero is reserved to mean no territory.
+type Territory struct {
+	ID     int
+	Centre *world.Vector
+	Radius float64
+}
+
+// Contains reports whether a position lies within the Territory
+func (t Territory) Contains(space *world.MetricSpace2D, position *world.Vector) bool {
+	return space.Metric(t.Centre, position) < t.Radius
+}
+
+// Ruler is the archetype of an agent that claims and defends space. It patrols its Territory and
+// catches intruders, either expelling them or making them its subjects. An unchallenged Territory
+// grows and one with intruders in it shrinks. A Ruler whose Territory shrinks away is deposed and
+// establishes a new one where it stands.
+type Ruler struct {
+	Territory        Territory
+	Establish        float64 // Radius of a newly established Territory
+	MinRadius        float64 // Radius below which the Territory is lost
+	MaxRadius        float64 // Radius the Territory cannot grow beyond
+	Growth           float64 // Rate the radius grows per second while there are no intruders
+	Decay            float64 // Rate the radius shrinks per second for each intruder
+	Reach            float64 // Distance within which an intruder is caught
+	PerceptionRadius float64 // Intruders further away than this are not pursued
+	Subjugate        bool    // Caught intruders become subjects instead of being expelled
+	MaxSpeed         float64 // The Ruler's speed is limited to this
+}
+
+// DefaultRuler returns a Ruler that expels intruders
+func DefaultRuler() Ruler {
+	return Ruler{
+		Establish:        80,
+		MinRadius:        20,
+		MaxRadius:        150,
+		Growth:           1,
+		Decay:            2,
+		Reach:            10,
+		PerceptionRadius: 100,
+		MaxSpeed:         maxSpeed,
+	}
+}
+
+// NewRuler initialises an agent with the Ruler archetype. Its Territory is established around the
+// point it starts at, once it has been placed.
+func NewRuler(positions, velocities *world.MetricSpace2D, random *utils.Random, ruler Ruler, territory int) *Agent {
+	agent := NewAgent(positions, velocities, random)
+	ruler.Territory = Territory{ID: territory, Centre: agent.Position.Copy(), Radius: ruler.Establish}
+	agent.Ruler = &ruler
+	agent.MaxSpeed = ruler.MaxSpeed
+	agent.Behaviours = []WeightedBehaviour{
+		{Behaviour: Reign{Limits{MaxSpeed: ruler.MaxSpeed, MaxForce: ruler.MaxSpeed}}, Weight: 1},
+	}
+	return agent
+}
+
+// WithRulers adds a group of count agents with the Ruler archetype to the Scenario, each with its
+// own Territory, placed by spawns in turn
+func WithRulers(count int, ruler Ruler, spawns ...Spawn) Option {
+	return populate(func(s *Scenario) {
+		group := make([]*Agent, count)
+		for index := range group {
+			s.state.territories++
+			group[index] = NewRuler(s.positions, s.velocities, s.random, ruler, s.state.territories)
+		}
+		s.spawn(group, spawns)
+		for _, agent := range group {
+			agent.Ruler.Territory.Centre = agent.Position.Copy()
+		}
+	})
+}
+
+// Intrudes reports whether agent is an intruder in the Territory of ruler
+func (r *Ruler) Intrudes(space *world.MetricSpace2D, ruler, agent *Agent) bool {
+	return agent != ruler && agent.Sovereign != ruler && r.Territory.Contains(space, agent.Position)
+}
+
+// Reign steers a Ruler towards the nearest intruder it perceives in its Territory, or when there
+// is none, around a patrol circle at three quarters of the Territory's radius.
+type Reign struct {
+	Limits
+}
+
+// Steer implements Behaviour
+func (r Reign) Steer(self *Agent, view View) world.Vector {
+	ruler := self.Ruler
+	if ruler == nil {
+		return *view.Velocities().ZeroVector()
+	}
+
+	var nearest *Neighbour
+	for _, other := range view.Neighbours(ruler.PerceptionRadius) {
+		other := other
+		if ruler.Intrudes(view.Space(), self, other.Agent) && (nearest == nil || other.Distance < nearest.Distance) {
+			nearest = &other
+		}
+	}
+	if nearest != nil {
+		return r.steer(self, nearest.Offset)
+	}
+
+	// Radial vector from the centre of the Territory to the Ruler
+	radial := view.Offset(ruler.Territory.Centre)
+	radial.Scale(-1)
+	distance := radial.Mag()
+	if distance == 0 {
+		return r.steer(self, *view.Velocities().NewVector(1, 0))
+	}
+	patrol := 0.75 * ruler.Territory.Radius
+	tangent := view.Velocities().NewVector(-radial.Y/distance, radial.X/distance)
+	correction := radial.Times((patrol - distance) / (patrol * distance))
+	return r.steer(self, *tangent.Accumulate(tangent, &correction))
+}
+
+// Allegiance steers a subject back into the Territory of its sovereign whenever it strays outside
+type Allegiance struct {
+	Limits
+}
+
+// Steer implements Behaviour
+func (a Allegiance) Steer(self *Agent, view View) world.Vector {
+	sovereign := self.Sovereign
+	if sovereign == nil || sovereign.Ruler.Territory.Contains(view.Space(), self.Position) {
+		return *view.Velocities().ZeroVector()
+	}
+	return a.steer(self, view.Offset(sovereign.Ruler.Territory.Centre))
+}
+
+// updateRulers catches intruders and grows or shrinks every Territory over dt seconds
+func (s *State) updateRulers(dt float64) {
+	for _, agent := range s.Agents {
+		ruler := agent.Ruler
+		if ruler == nil {
+			continue
+		}
+
+		intruders := 0
+		for _, candidate := range s.WithinRadius(ruler.Territory.Centre, ruler.Territory.Radius) {
+			other := s.Agents[candidate]
+			if !ruler.Intrudes(s.CoordinateSystem, agent, other) {
+				continue
+			}
+			if s.CoordinateSystem.Metric(agent.Position, other.Position) > ruler.Reach {
+				intruders++
+			} else if ruler.Subjugate && other.Ruler == nil {
+				s.subjugate(agent, other)
+			} else {
+				s.expel(ruler, other)
+			}
+		}
+
+		if intruders == 0 {
+			ruler.Territory.Radius += ruler.Growth * dt
+		} else {
+			ruler.Territory.Radius -= ruler.Decay * float64(intruders) * dt
+		}
+		ruler.Territory.Radius = math.Min(ruler.Territory.Radius, ruler.MaxRadius)
+
+		if ruler.Territory.Radius < ruler.MinRadius {
+			ruler.Territory.Centre = agent.Position.Copy()
+			ruler.Territory.Radius = ruler.Establish
+		}
+	}
+}
+
+// subjugate makes agent a subject of sovereign, it will no longer intrude in their Territory. An
+// agent that was already a subject of another Ruler changes its allegiance rather than adding to it.
+func (s *State) subjugate(sovereign, agent *Agent) {
+	agent.Sovereign = sovereign
+	for _, weighted := range agent.Behaviours {
+		if _, ok := weighted.Behaviour.(Allegiance); ok {
+			return
+		}
+	}
+	limits := Limits{MaxSpeed: sovereign.Ruler.MaxSpeed, MaxForce: sovereign.Ruler.MaxSpeed}
+	if agent.MaxSpeed > 0 {
+		limits.MaxSpeed = agent.MaxSpeed
+	}
+	agent.Behaviours = append(agent.Behaviours, WeightedBehaviour{Behaviour: Allegiance{limits}, Weight: 1})
+}
+
+// expel turns an intruder around so that it heads directly out of the Territory at no less than
+// its current speed
+func (s *State) expel(ruler *Ruler, agent *Agent) {
+	deltaX, deltaY := s.CoordinateSystem.GeodesicDiff(
+		ruler.Territory.Centre.X, agent.Position.X, ruler.Territory.Centre.Y, agent.Position.Y,
+	)
+	outwards := s.Velocities.NewVector(deltaX, deltaY)
+	if outwards.Mag() == 0 {
+		outwards = s.Velocities.NewVector(1, 0)
+	}
+	speed := math.Max(agent.Velocity.Mag(), ruler.MaxSpeed)
+	if agent.MaxSpeed > 0 {
+		speed = math.Min(speed, agent.MaxSpeed)
+	}
+	agent.Velocity.Accumulate(outwards.SetMag(speed))
+}
+
+// rulers returns the agents with the Ruler archetype
+func (s State) rulers() []*Agent {
+	rulers := make([]*Agent, 0)
+	for _, agent := range s.Agents {
+		if agent.Ruler != nil {
+			rulers = append(rulers, agent)
+		}
+	}
+	return rulers
+}
+
+// territoryOf returns the ID of the Territory of one of the rulers containing position, or zero if
+// there is none. Where territories overlap the one whose centre is nearest wins.
+func (s State) territoryOf(rulers []*Agent, position *world.Vector) int {
+	id, nearest := 0, math.Inf(1)
+	for _, agent := range rulers {
+		if !agent.Ruler.Territory.Contains(s.CoordinateSystem, position) {
+			continue
+		}
+		if distance := s.CoordinateSystem.Metric(agent.Ruler.Territory.Centre, position); distance < nearest {
+			id, nearest = agent.Ruler.Territory.ID, distance
+		}
+	}
+	return id
+}
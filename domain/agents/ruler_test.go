@@ -0,0 +1,119 @@
+package agents
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// realm places a stationary Ruler at the origin alongside one stationary plain agent
+func realm(ruler Ruler, x, y float64) *Scenario {
+	scenario := InitialiseScenario(time.Second/10, WithRulers(1, ruler))
+	population := scenario.state.Population()
+	scenario.state.Agents = scenario.state.Agents[population-2:]
+	king, commoner := scenario.state.Agents[1], scenario.state.Agents[0]
+	king.Position.X, king.Position.Y = 0, 0
+	king.Ruler.Territory.Centre = king.Position.Copy()
+	king.Velocity.X, king.Velocity.Y = 0, 0
+	king.Behaviours = nil
+	commoner.Position.X, commoner.Position.Y = x, y
+	commoner.Velocity.X, commoner.Velocity.Y = 0, 0
+	return scenario
+}
+
+func TestTerritory_ContainsAcrossSeam(t *testing.T) {
+	scenario := realm(DefaultRuler(), -20, -20)
+	king := scenario.state.Agents[1]
+
+	assert.True(t, king.Ruler.Territory.Contains(scenario.positions, scenario.positions.NewVector(width-20, height-20)))
+	assert.False(t, king.Ruler.Territory.Contains(scenario.positions, scenario.positions.NewVector(width/2, 0)))
+}
+
+func TestRuler_ExpelsCaughtIntruders(t *testing.T) {
+	scenario := realm(DefaultRuler(), -5, 0)
+	commoner := scenario.state.Agents[0]
+
+	scenario.Step()
+
+	assert.Less(t, commoner.Velocity.X, 0.0)
+	assert.InDelta(t, 0.0, commoner.Velocity.Y, MaxPrecision)
+}
+
+func TestRuler_SubjugatesCaughtIntruders(t *testing.T) {
+	ruler := DefaultRuler()
+	ruler.Subjugate = true
+	scenario := realm(ruler, 5, 0)
+	king, commoner := scenario.state.Agents[1], scenario.state.Agents[0]
+
+	scenario.Step()
+
+	assert.Same(t, king, commoner.Sovereign)
+	assert.False(t, king.Ruler.Intrudes(scenario.positions, king, commoner))
+}
+
+func TestRuler_SubjugationChangesAllegiance(t *testing.T) {
+	ruler := DefaultRuler()
+	ruler.Subjugate = true
+	scenario := realm(ruler, 5, 0)
+	king, commoner := scenario.state.Agents[1], scenario.state.Agents[0]
+	usurper := NewRuler(scenario.positions, scenario.velocities, scenario.random, ruler, 2)
+	behaviours := len(commoner.Behaviours)
+
+	for _, sovereign := range []*Agent{king, usurper, king} {
+		scenario.state.subjugate(sovereign, commoner)
+	}
+
+	assert.Same(t, king, commoner.Sovereign)
+	assert.Len(t, commoner.Behaviours, behaviours+1, "a subject has one Allegiance")
+}
+
+func TestRuler_TerritoryGrowsWhenUnchallenged(t *testing.T) {
+	scenario := realm(DefaultRuler(), width/2, height/2)
+	king := scenario.state.Agents[1]
+
+	scenario.Step()
+
+	assert.InDelta(t, king.Ruler.Establish+king.Ruler.Growth/10, king.Ruler.Territory.Radius, MaxPrecision)
+}
+
+func TestRuler_TerritoryShrinksWithIntruders(t *testing.T) {
+	scenario := realm(DefaultRuler(), 50, 0)
+	king := scenario.state.Agents[1]
+
+	scenario.Step()
+
+	assert.InDelta(t, king.Ruler.Establish-king.Ruler.Decay/10, king.Ruler.Territory.Radius, MaxPrecision)
+}
+
+func TestRuler_EstablishesNewTerritoryWhenLost(t *testing.T) {
+	scenario := realm(DefaultRuler(), 10, 0)
+	king := scenario.state.Agents[1]
+	king.Ruler.Territory.Radius = king.Ruler.MinRadius
+	king.Position.X = 30
+
+	scenario.Step()
+
+	assert.Equal(t, king.Ruler.Establish, king.Ruler.Territory.Radius)
+	assert.Equal(t, 30.0, king.Ruler.Territory.Centre.X)
+}
+
+func TestState_Frame_ExposesTerritory(t *testing.T) {
+	scenario := realm(DefaultRuler(), 50, 0)
+	king := scenario.state.Agents[1]
+
+	frame := scenario.state.Frame()
+
+	assert.Equal(t, king.Ruler.Territory.ID, frame[0].Territory)
+	assert.Equal(t, king.Ruler.Territory.ID, frame[1].Realm.ID)
+	assert.Equal(t, king.Ruler.Territory.Radius, frame[1].Realm.Radius.Value)
+	assert.Nil(t, frame[0].Realm)
+}
+
+func TestReign_PursuesIntruders(t *testing.T) {
+	scenario := realm(DefaultRuler(), -40, 0)
+	king := scenario.state.Agents[1]
+
+	steering := Reign{Limits{MaxSpeed: 5, MaxForce: 5}}.Steer(king, scenario.state.NewView(1))
+
+	assert.InDelta(t, -5.0, steering.X, MaxPrecision)
+}
@@ -0,0 +1,428 @@
+package agents
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"time"
+	"tjweldon/archetypal-agents/domain/world"
+	"tjweldon/archetypal-agents/utils"
+	"tjweldon/archetypal-agents/worlds"
+)
+
+// Snapshot is a complete, serialisable record of a Scenario at an instant. A Scenario restored from
+// it follows exactly the trajectory the original would have. Agents refer to one another by their
+// index in Agents, and Behaviours and the Integrator by the names they are registered under.
+type Snapshot struct {
+	Time, DeltaT          time.Duration
+	Seed                  int64
+	Random                utils.Random // The state of the Scenario's source of randomness
+	Integrator            string
+	Positions, Velocities world.Topology2D
+	CellSize              float64
+	Territories           int // The number of territories established so far
+	IDs                   int // The number of agent IDs assigned so far
+	Groups                int // The number of group IDs assigned so far
+	Agents                []AgentRecord
+	Bonds                 []BondRecord
+	Walls                 []worlds.Wall // The walls of the Maze, nil if there is no Maze
+	Cells                 []worlds.Cell // The cells of the Maze
+	Flow                  *worlds.FlowField
+	Patches               []worlds.Patch // The resource patches, nil if there are no Resources
+	Collisions            *Collisions
+	Clustering            *Clustering
+}
+
+// AgentRecord is the record of an Agent in a Snapshot
+type AgentRecord struct {
+	ID                 int
+	Position, Velocity *world.Vector
+	MaxSpeed           float64
+	Heading            float64
+	AngularVelocity    float64
+	TurnRate           float64
+	Random             *utils.Random
+	Behaviours         []BehaviourRecord
+	Lover              *LoverRecord
+	Ruler              *Ruler
+	Predator           *Predator
+	Prey               *Prey
+	Sovereign          *int
+	Age                float64
+	Lifecycle          *Lifecycle
+	Metabolism         *Metabolism
+	Perception         *Perception
+	Body               *Body
+	Group              int
+}
+
+// archetype returns the archetype of the recorded agent, see Agent.Archetype
+func (r AgentRecord) archetype() string {
+	switch {
+	case r.Lover != nil:
+		return "lover"
+	case r.Ruler != nil:
+		return "ruler"
+	case r.Predator != nil:
+		return "predator"
+	case r.Prey != nil:
+		return "prey"
+	default:
+		return "agent"
+	}
+}
+
+// BehaviourRecord is the record of a WeightedBehaviour, the Behaviour is recorded by its Kind,
+// the name it is registered under, and its fields
+type BehaviourRecord struct {
+	Kind   string
+	Weight float64
+	Fields json.RawMessage
+}
+
+// LoverRecord is the record of a Lover, with the agents it refers to replaced by their indices
+type LoverRecord struct {
+	Lover
+	Partner, Courting, Spurned *int
+}
+
+// BondRecord is the record of a Bond between the agents at indices A and B
+type BondRecord struct {
+	BondSpec
+	A, B int
+}
+
+// Snapshot records the Scenario as it is now. It fails if an agent has a Behaviour or the
+// Scenario has an Integrator whose type has not been registered.
+func (s *Scenario) Snapshot() (*Snapshot, error) {
+	integrator, err := integratorTypes.name(s.integrator)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &Snapshot{
+		Time:        s.Time,
+		DeltaT:      s.DeltaT,
+		Seed:        s.Seed,
+		Random:      *s.random,
+		Integrator:  integrator,
+		Positions:   s.positions.Topology(),
+		Velocities:  s.velocities.Topology(),
+		CellSize:    s.state.CellSize,
+		Territories: s.state.territories,
+		IDs:         s.state.ids,
+		Groups:      s.state.groups,
+		Agents:      make([]AgentRecord, s.state.Population()),
+		Flow:        s.state.Flow,
+		Collisions:  s.state.Collisions,
+		Clustering:  s.state.Clustering,
+	}
+	if s.state.Maze != nil {
+		snapshot.Walls = append([]worlds.Wall{}, s.state.Maze.Walls...)
+		snapshot.Cells = append([]worlds.Cell{}, s.state.Maze.Cells...)
+	}
+	if s.state.Resources != nil {
+		snapshot.Patches = append([]worlds.Patch{}, s.state.Resources.Patches...)
+	}
+
+	indices := make(map[*Agent]int, s.state.Population())
+	for index, agent := range s.state.Agents {
+		indices[agent] = index
+	}
+	reference := func(agent *Agent) (*int, error) {
+		if agent == nil {
+			return nil, nil
+		}
+		index, ok := indices[agent]
+		if !ok {
+			return nil, fmt.Errorf("reference to an agent that is not in the Scenario")
+		}
+		return &index, nil
+	}
+
+	for index, agent := range s.state.Agents {
+		record, err := agent.record(reference)
+		if err != nil {
+			return nil, fmt.Errorf("agent %d: %w", index, err)
+		}
+		snapshot.Agents[index] = record
+	}
+	for _, bond := range s.state.Bonds {
+		snapshot.Bonds = append(snapshot.Bonds, BondRecord{BondSpec: bond.BondSpec, A: indices[bond.A], B: indices[bond.B]})
+	}
+	return snapshot, nil
+}
+
+// record records the agent, reference finds the index of the agents it refers to
+func (a *Agent) record(reference func(agent *Agent) (*int, error)) (record AgentRecord, err error) {
+	record = AgentRecord{
+		ID:              a.ID,
+		Position:        a.Position.Copy(),
+		Velocity:        a.Velocity.Copy(),
+		MaxSpeed:        a.MaxSpeed,
+		Heading:         a.Heading,
+		AngularVelocity: a.AngularVelocity,
+		TurnRate:        a.TurnRate,
+		Age:             a.Age,
+		Group:           a.Group,
+	}
+	if a.Random != nil {
+		record.Random = a.Random.Copy()
+	}
+	for _, weighted := range a.Behaviours {
+		kind, err := behaviourTypes.name(weighted.Behaviour)
+		if err != nil {
+			return record, err
+		}
+		fields, err := json.Marshal(weighted.Behaviour)
+		if err != nil {
+			return record, err
+		}
+		record.Behaviours = append(record.Behaviours, BehaviourRecord{Kind: kind, Weight: weighted.Weight, Fields: fields})
+	}
+	if a.Lover != nil {
+		record.Lover = &LoverRecord{Lover: *a.Lover}
+		record.Lover.Lover.Partner, record.Lover.Lover.Courting, record.Lover.Lover.Spurned = nil, nil, nil
+		for field, agent := range map[**int]*Agent{
+			&record.Lover.Partner:  a.Lover.Partner,
+			&record.Lover.Courting: a.Lover.Courting,
+			&record.Lover.Spurned:  a.Lover.Spurned,
+		} {
+			if *field, err = reference(agent); err != nil {
+				return record, err
+			}
+		}
+	}
+	if a.Ruler != nil {
+		ruler := *a.Ruler
+		ruler.Territory.Centre = ruler.Territory.Centre.Copy()
+		record.Ruler = &ruler
+	}
+	if a.Predator != nil {
+		predator := *a.Predator
+		record.Predator = &predator
+	}
+	if a.Prey != nil {
+		prey := *a.Prey
+		record.Prey = &prey
+	}
+	if a.Lifecycle != nil {
+		lifecycle := *a.Lifecycle
+		record.Lifecycle = &lifecycle
+	}
+	if a.Metabolism != nil {
+		metabolism := *a.Metabolism
+		record.Metabolism = &metabolism
+	}
+	if a.Perception != nil {
+		perception := *a.Perception
+		record.Perception = &perception
+	}
+	if a.Body != nil {
+		body := *a.Body
+		record.Body = &body
+	}
+	record.Sovereign, err = reference(a.Sovereign)
+	return record, err
+}
+
+// Restore rebuilds the Scenario recorded in the Snapshot
+func Restore(snapshot *Snapshot) (*Scenario, error) {
+	if snapshot.DeltaT <= 0 {
+		return nil, fmt.Errorf("time step must be positive, not %v", snapshot.DeltaT)
+	}
+	positions, err := snapshot.Positions.Space()
+	if err != nil {
+		return nil, fmt.Errorf("positions: %w", err)
+	}
+	velocities, err := snapshot.Velocities.Space()
+	if err != nil {
+		return nil, fmt.Errorf("velocities: %w", err)
+	}
+	integrator, err := integratorTypes.build(snapshot.Integrator, nil)
+	if err != nil {
+		return nil, fmt.Errorf("integrator: %w", err)
+	}
+	random := snapshot.Random
+
+	state := &State{
+		CoordinateSystem: positions,
+		Velocities:       velocities,
+		Agents:           make([]*Agent, len(snapshot.Agents)),
+		CellSize:         snapshot.CellSize,
+		Flow:             snapshot.Flow,
+		Collisions:       snapshot.Collisions,
+		Clustering:       snapshot.Clustering,
+		territories:      snapshot.Territories,
+		ids:              snapshot.IDs,
+		groups:           snapshot.Groups,
+		random:           &random,
+	}
+	if snapshot.Walls != nil {
+		state.Maze = worlds.NewMaze(positions, snapshot.Walls...)
+		if len(snapshot.Cells) > 0 {
+			state.Maze.Cells = append([]worlds.Cell{}, snapshot.Cells...)
+		}
+	}
+	if snapshot.Patches != nil {
+		state.Resources = worlds.NewResources(positions, append([]worlds.Patch{}, snapshot.Patches...)...)
+	}
+	for index := range state.Agents {
+		state.Agents[index] = &Agent{}
+	}
+	resolve := func(index *int, archetype string) (*Agent, error) {
+		if index == nil {
+			return nil, nil
+		}
+		if *index < 0 || *index >= len(state.Agents) {
+			return nil, fmt.Errorf("reference to agent %d of %d", *index, len(state.Agents))
+		}
+		if recorded := snapshot.Agents[*index].archetype(); archetype != "" && recorded != archetype {
+			return nil, fmt.Errorf("reference to agent %d, whose archetype is %s rather than %s", *index, recorded, archetype)
+		}
+		return state.Agents[*index], nil
+	}
+
+	for index, record := range snapshot.Agents {
+		if err := state.Agents[index].restore(record, positions, velocities, resolve); err != nil {
+			return nil, fmt.Errorf("agent %d: %w", index, err)
+		}
+		if state.Agents[index].Random == nil {
+			state.Agents[index].Random = state.random.Split()
+		}
+	}
+	for index, record := range snapshot.Bonds {
+		a, errA := resolve(&record.A, "")
+		b, errB := resolve(&record.B, "")
+		if errA != nil || errB != nil || a == b {
+			return nil, fmt.Errorf("bond %d joins invalid agents %d and %d", index, record.A, record.B)
+		}
+		state.FormBond(a, b, record.BondSpec)
+	}
+	state.BondEvents = nil
+	state.pool = newPool(runtime.GOMAXPROCS(0))
+
+	return &Scenario{
+		Time:       snapshot.Time,
+		DeltaT:     snapshot.DeltaT,
+		Seed:       snapshot.Seed,
+		positions:  positions,
+		velocities: velocities,
+		state:      state,
+		integrator: integrator.(Integrator),
+		workers:    runtime.GOMAXPROCS(0),
+		random:     &random,
+	}, nil
+}
+
+// restore sets the agent from its record, resolve finds the agents it refers to and checks they
+// are of the archetype given, if any
+func (a *Agent) restore(
+	record AgentRecord,
+	positions, velocities *world.MetricSpace2D,
+	resolve func(index *int, archetype string) (*Agent, error),
+) (err error) {
+	if record.Position == nil || record.Velocity == nil {
+		return fmt.Errorf("missing position or velocity")
+	}
+	a.Position = positions.NewVector(record.Position.X, record.Position.Y)
+	a.Velocity = velocities.NewVector(record.Velocity.X, record.Velocity.Y)
+	a.ID, a.MaxSpeed, a.Age = record.ID, record.MaxSpeed, record.Age
+	a.Heading, a.AngularVelocity, a.TurnRate = record.Heading, record.AngularVelocity, record.TurnRate
+	a.Group = record.Group
+	if record.Lifecycle != nil {
+		lifecycle := *record.Lifecycle
+		a.Lifecycle = &lifecycle
+	}
+	if record.Metabolism != nil {
+		metabolism := *record.Metabolism
+		a.Metabolism = &metabolism
+	}
+	if record.Perception != nil {
+		perception := *record.Perception
+		a.Perception = &perception
+	}
+	if record.Body != nil {
+		body := *record.Body
+		a.Body = &body
+	}
+	if record.Random != nil {
+		a.Random = record.Random.Copy()
+	}
+	for _, behaviour := range record.Behaviours {
+		built, err := behaviourTypes.build(behaviour.Kind, behaviour.Fields)
+		if err != nil {
+			return err
+		}
+		a.Behaviours = append(a.Behaviours, WeightedBehaviour{Behaviour: rebind(built.(Behaviour), positions), Weight: behaviour.Weight})
+	}
+	if record.Lover != nil {
+		lover := record.Lover.Lover
+		for field, index := range map[**Agent]*int{
+			&lover.Partner:  record.Lover.Partner,
+			&lover.Courting: record.Lover.Courting,
+			&lover.Spurned:  record.Lover.Spurned,
+		} {
+			if *field, err = resolve(index, "lover"); err != nil {
+				return err
+			}
+		}
+		a.Lover = &lover
+	}
+	if record.Ruler != nil {
+		ruler := *record.Ruler
+		if centre := ruler.Territory.Centre; centre != nil {
+			ruler.Territory.Centre = positions.NewVector(centre.X, centre.Y)
+		}
+		a.Ruler = &ruler
+	}
+	if record.Predator != nil {
+		predator := *record.Predator
+		a.Predator = &predator
+	}
+	if record.Prey != nil {
+		prey := *record.Prey
+		a.Prey = &prey
+	}
+	a.Sovereign, err = resolve(record.Sovereign, "ruler")
+	return err
+}
+
+// rebind places the vectors of a decoded Behaviour back in the position space, decoding only
+// recovers their coordinates
+func rebind(behaviour Behaviour, positions *world.MetricSpace2D) Behaviour {
+	switch concrete := behaviour.(type) {
+	case Seek:
+		if concrete.Target != nil {
+			concrete.Target = positions.NewVector(concrete.Target.X, concrete.Target.Y)
+		}
+		return concrete
+	case Flee:
+		if concrete.Target != nil {
+			concrete.Target = positions.NewVector(concrete.Target.X, concrete.Target.Y)
+		}
+		return concrete
+	default:
+		return behaviour
+	}
+}
+
+// Save writes a Snapshot of the Scenario to w as JSON
+func (s *Scenario) Save(w io.Writer) error {
+	snapshot, err := s.Snapshot()
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(snapshot)
+}
+
+// Load reads a Snapshot written by Save from r and restores the Scenario it records
+func Load(r io.Reader) (*Scenario, error) {
+	var snapshot Snapshot
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return nil, err
+	}
+	return Restore(&snapshot)
+}
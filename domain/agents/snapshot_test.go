@@ -0,0 +1,101 @@
+package agents
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestScenario_Load_ContinuesTrajectory(t *testing.T) {
+	subjugating := DefaultRuler()
+	subjugating.Subjugate = true
+	for name, integrator := range integrators {
+		original := InitialiseScenario(
+			time.Second/60,
+			WithFlocking(DefaultFlocking()),
+			WithLovers(10, DefaultLover()),
+			WithRulers(3, subjugating),
+			WithMaze(8, 4),
+			WithTurbulence(6, 5),
+			WithBodies(DefaultBody()),
+			WithCollisions(0.5),
+			WithClustering(DefaultClustering()),
+			WithIntegrator(integrator),
+			WithSeed(7),
+		)
+		target := original.positions.NewVector(400, 200)
+		limits := Limits{MaxSpeed: maxSpeed, MaxForce: maxSpeed}
+		original.state.Agents[0].Behaviours = append(
+			original.state.Agents[0].Behaviours,
+			WeightedBehaviour{Behaviour: Seek{limits, target}, Weight: 1},
+			WeightedBehaviour{Behaviour: Flee{limits, target, 50}, Weight: 1},
+			WeightedBehaviour{Behaviour: Wander{limits, 0.5}, Weight: 1},
+		)
+		for range [300]any{} {
+			original.Step()
+		}
+
+		var saved bytes.Buffer
+		assert.NoError(t, original.Save(&saved), name)
+		restored, err := Load(&saved)
+		assert.NoError(t, err, name)
+
+		assert.Equal(t, original.Time, restored.Time, name)
+		assert.Equal(t, original.Seed, restored.Seed, name)
+		assert.Equal(t, original.Walls(), restored.Walls(), name)
+		for range [300]any{} {
+			assert.Equal(t, original.GetNextFrame(), restored.GetNextFrame(), name)
+		}
+		assert.Equal(t, len(original.state.Bonds), len(restored.state.Bonds), name)
+	}
+}
+
+func TestScenario_Snapshot_RequiresRegisteredBehaviours(t *testing.T) {
+	scenario := InitialiseScenario(time.Second / 60)
+	scenario.state.Agents[0].Behaviours = []WeightedBehaviour{{Behaviour: constant{1, 0}, Weight: 1}}
+
+	_, err := scenario.Snapshot()
+	assert.Error(t, err)
+}
+
+func TestRestore_RejectsInvalidReferences(t *testing.T) {
+	snapshot, err := InitialiseScenario(time.Second/60, WithLovers(2, DefaultLover())).Snapshot()
+	assert.NoError(t, err)
+	missing := len(snapshot.Agents)
+	snapshot.Agents[missing-1].Lover.Partner = &missing
+
+	_, err = Restore(snapshot)
+	assert.Error(t, err)
+}
+
+func TestRestore_RejectsTimeStepsThatNeverAdvance(t *testing.T) {
+	snapshot, err := InitialiseScenario(time.Second/60, WithPopulation(1)).Snapshot()
+	assert.NoError(t, err)
+	snapshot.DeltaT = 0
+
+	_, err = Restore(snapshot)
+	assert.Error(t, err)
+	assert.Panics(t, func() { InitialiseScenario(0) })
+}
+
+func TestRestore_RejectsReferencesToOtherArchetypes(t *testing.T) {
+	scenario := InitialiseScenario(time.Second/60, WithPopulation(1), WithLovers(1, DefaultLover()), WithRulers(1, DefaultRuler()))
+	plain, lover, ruler := 0, 1, 2
+
+	snapshot, err := scenario.Snapshot()
+	assert.NoError(t, err)
+	snapshot.Agents[lover].Lover.Courting = &plain
+	_, err = Restore(snapshot)
+	assert.EqualError(t, err, "agent 1: reference to agent 0, whose archetype is agent rather than lover")
+
+	snapshot, _ = scenario.Snapshot()
+	snapshot.Agents[plain].Sovereign = &lover
+	_, err = Restore(snapshot)
+	assert.EqualError(t, err, "agent 0: reference to agent 1, whose archetype is lover rather than ruler")
+
+	snapshot, _ = scenario.Snapshot()
+	snapshot.Agents[plain].Sovereign = &ruler
+	_, err = Restore(snapshot)
+	assert.NoError(t, err)
+}
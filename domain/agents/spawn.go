@@ -0,0 +1,130 @@
+package agents
+
+import (
+	"math"
+	"tjweldon/archetypal-agents/utils"
+)
+
+// Bounds is the rectangle [0, Width) x [0, Height) that agents are spawned in
+type Bounds struct {
+	Width, Height float64
+}
+
+// Spawn is a strategy for the initial positions and velocities of a group of agents. Most
+// strategies set only one of the two and leave the other alone, so they compose by applying them
+// to the group in turn, eg. Clusters then Heading for flocks that set off together. Agents that
+// no strategy gives a velocity start stationary.
+type Spawn interface {
+	Spawn(group []*Agent, bounds Bounds, random *utils.Random)
+}
+
+// Uniform places agents uniformly at random over the bounds, moving in random directions at
+// speeds of up to MaxSpeed. It sets both positions and velocities.
+type Uniform struct {
+	MaxSpeed float64
+}
+
+// Spawn implements Spawn
+func (u Uniform) Spawn(group []*Agent, bounds Bounds, random *utils.Random) {
+	for _, agent := range group {
+		agent.Position.X, agent.Position.Y = random.Float(0, bounds.Width), random.Float(0, bounds.Height)
+
+		// Use plane polar for initial randomisation since that's easier when a max magnitude is imposed
+		r, theta := random.Float(0, u.MaxSpeed), random.Float(0, 2*math.Pi)
+		agent.Velocity.X, agent.Velocity.Y = r*math.Cos(theta), r*math.Sin(theta)
+	}
+}
+
+// Clusters places agents in Count Gaussian clusters whose centres are uniformly distributed over
+// the bounds. Spread is the standard deviation of the distance of an agent from its centre along
+// each axis. Agents are dealt to the clusters in turn, so the clusters are equally populated.
+type Clusters struct {
+	Count  int
+	Spread float64
+}
+
+// Spawn implements Spawn
+func (c Clusters) Spawn(group []*Agent, bounds Bounds, random *utils.Random) {
+	count := c.Count
+	if count < 1 {
+		count = 1
+	}
+	centres := make([][2]float64, count)
+	for index := range centres {
+		centres[index] = [2]float64{random.Float(0, bounds.Width), random.Float(0, bounds.Height)}
+	}
+	for index, agent := range group {
+		centre := centres[index%count]
+		agent.Position.X, agent.Position.Y = random.Normal(centre[0], c.Spread), random.Normal(centre[1], c.Spread)
+	}
+}
+
+// Ring places agents evenly around a circle of Radius about (X, Y), each displaced radially by up
+// to half of Width either way
+type Ring struct {
+	X, Y, Radius, Width float64
+}
+
+// Spawn implements Spawn
+func (r Ring) Spawn(group []*Agent, bounds Bounds, random *utils.Random) {
+	for index, agent := range group {
+		theta := 2 * math.Pi * float64(index) / float64(len(group))
+		radius := r.Radius + random.Float(-r.Width/2, r.Width/2)
+		agent.Position.X, agent.Position.Y = r.X+radius*math.Cos(theta), r.Y+radius*math.Sin(theta)
+	}
+}
+
+// Grid places agents on the points of a square lattice, each displaced by up to Jitter along each
+// axis. The lattice is as coarse as it can be while fitting the whole group within the bounds, and
+// is filled row by row.
+type Grid struct {
+	Jitter float64
+}
+
+// Spawn implements Spawn
+func (g Grid) Spawn(group []*Agent, bounds Bounds, random *utils.Random) {
+	if len(group) == 0 {
+		return
+	}
+	// The largest spacing at which the lattice has a point for every agent
+	spacing := math.Sqrt(bounds.Width * bounds.Height / float64(len(group)))
+	columns := int(math.Ceil(bounds.Width / spacing))
+	for columns*int(math.Floor(bounds.Height/(bounds.Width/float64(columns)))) < len(group) {
+		columns++
+	}
+	spacing = bounds.Width / float64(columns)
+	for index, agent := range group {
+		column, row := index%columns, index/columns
+		agent.Position.X = (float64(column)+0.5)*spacing + random.Float(-g.Jitter, g.Jitter)
+		agent.Position.Y = (float64(row)+0.5)*spacing + random.Float(-g.Jitter, g.Jitter)
+	}
+}
+
+// Heading sets every agent moving at Speed in the direction Angle, perturbed by up to Jitter either
+// way. Angles are in radians from the x axis towards the y axis.
+type Heading struct {
+	Angle, Speed, Jitter float64
+}
+
+// Spawn implements Spawn
+func (h Heading) Spawn(group []*Agent, bounds Bounds, random *utils.Random) {
+	for _, agent := range group {
+		angle := h.Angle + random.Float(-h.Jitter, h.Jitter)
+		agent.Velocity.X, agent.Velocity.Y = h.Speed*math.Cos(angle), h.Speed*math.Sin(angle)
+	}
+}
+
+// spawn places a newly created group of agents using spawns in turn, or Uniform if there are
+// none, and adds them to the State
+func (s *Scenario) spawn(group []*Agent, spawns []Spawn) {
+	if len(spawns) == 0 {
+		spawns = []Spawn{Uniform{MaxSpeed: maxSpeed}}
+	}
+	for _, spawn := range spawns {
+		spawn.Spawn(group, s.bounds, s.random)
+	}
+	for _, agent := range group {
+		agent.face()
+		s.state.Add(agent)
+	}
+}
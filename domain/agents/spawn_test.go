@@ -0,0 +1,117 @@
+package agents
+
+import (
+	"github.com/stretchr/testify/assert"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+	"time"
+	"tjweldon/archetypal-agents/domain/world"
+)
+
+// spawned returns a group of count plain agents placed by spawns in an 800 x 400 plane
+func spawned(count int, spawns ...Spawn) []*Agent {
+	scenario := InitialiseScenario(
+		time.Second/60,
+		WithWorld(800, 400, world.NewEuclideanPlane()),
+		WithPopulation(0),
+		WithAgents(count, spawns...),
+	)
+	return scenario.state.Agents
+}
+
+func TestUniform_Spawn_WithinBounds(t *testing.T) {
+	scenario := InitialiseScenario(
+		time.Second/60,
+		WithWorld(200, 100, world.NewEuclideanPlane()),
+		WithPopulation(0),
+		WithAgents(500, Uniform{MaxSpeed: 3}),
+	)
+
+	assert.Equal(t, 500, scenario.state.Population())
+	for _, agent := range scenario.state.Agents {
+		assert.True(t, agent.Position.X >= 0 && agent.Position.X < 200)
+		assert.True(t, agent.Position.Y >= 0 && agent.Position.Y < 100)
+		assert.LessOrEqual(t, agent.Velocity.Mag(), 3.0)
+	}
+}
+
+func TestWithRulers_EstablishesTerritoryWhereSpawned(t *testing.T) {
+	scenario := InitialiseScenario(time.Second/60, WithPopulation(0), WithRulers(3, DefaultRuler()))
+
+	for _, agent := range scenario.state.Agents {
+		assert.Equal(t, agent.Position.X, agent.Ruler.Territory.Centre.X)
+		assert.Equal(t, agent.Position.Y, agent.Ruler.Territory.Centre.Y)
+	}
+}
+
+func TestWithFlocking_OnlyPlainAgentsFlock(t *testing.T) {
+	scenario := InitialiseScenario(time.Second/60, WithLovers(2, DefaultLover()), WithFlocking(DefaultFlocking()))
+
+	for _, agent := range scenario.state.Agents {
+		if agent.Lover != nil {
+			assert.Len(t, agent.Behaviours, 2)
+		} else {
+			assert.Len(t, agent.Behaviours, 3)
+		}
+	}
+}
+
+func TestClusters_Spawn_AroundCentres(t *testing.T) {
+	group := spawned(300, Clusters{Count: 3, Spread: 5})
+
+	// Agents are dealt to the clusters in turn, so every third agent shares a centre
+	for cluster := 0; cluster < 3; cluster++ {
+		meanX, meanY := 0.0, 0.0
+		for index := cluster; index < len(group); index += 3 {
+			meanX, meanY = meanX+group[index].Position.X/100, meanY+group[index].Position.Y/100
+		}
+		for index := cluster; index < len(group); index += 3 {
+			assert.Less(t, math.Hypot(group[index].Position.X-meanX, group[index].Position.Y-meanY), 30.0)
+		}
+	}
+}
+
+func TestRing_Spawn_OnTheRing(t *testing.T) {
+	for _, agent := range spawned(100, Ring{X: 400, Y: 200, Radius: 100, Width: 10}) {
+		distance := math.Hypot(agent.Position.X-400, agent.Position.Y-200)
+		assert.InDelta(t, 100, distance, 5)
+	}
+}
+
+func TestGrid_Spawn_Lattice(t *testing.T) {
+	group := spawned(200, Grid{})
+
+	seen := make(map[[2]float64]bool)
+	for _, agent := range group {
+		assert.True(t, agent.Position.X > 0 && agent.Position.X < 800)
+		assert.True(t, agent.Position.Y > 0 && agent.Position.Y < 400)
+		seen[[2]float64{agent.Position.X, agent.Position.Y}] = true
+	}
+	assert.Len(t, seen, 200)
+	assert.InDelta(t, 2*group[0].Position.X, group[1].Position.X-group[0].Position.X, MaxPrecision)
+}
+
+func TestDensity_Spawn_AvoidsBlack(t *testing.T) {
+	picture := image.NewGray(image.Rect(0, 0, 4, 2))
+	picture.SetGray(3, 1, color.Gray{Y: 255})
+	density, err := NewDensity(picture)
+	assert.NoError(t, err)
+
+	for _, agent := range spawned(100, density) {
+		assert.True(t, agent.Position.X >= 600 && agent.Position.X < 800)
+		assert.True(t, agent.Position.Y >= 200 && agent.Position.Y < 400)
+	}
+
+	_, err = NewDensity(image.NewGray(image.Rect(0, 0, 4, 2)))
+	assert.Error(t, err)
+}
+
+func TestSpawn_Composes(t *testing.T) {
+	for _, agent := range spawned(50, Ring{X: 400, Y: 200, Radius: 50}, Heading{Angle: math.Pi / 2, Speed: 4}) {
+		assert.InDelta(t, 50, math.Hypot(agent.Position.X-400, agent.Position.Y-200), MaxPrecision)
+		assert.InDelta(t, 0, agent.Velocity.X, MaxPrecision)
+		assert.InDelta(t, 4, agent.Velocity.Y, MaxPrecision)
+	}
+}
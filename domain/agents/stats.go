@@ -0,0 +1,37 @@
+package agents
+
+// Stats summarise a Scenario after a step, they are a part of the socket API (see comment on Coords)
+// for charting the dynamics of the population, eg. the oscillations of predators and prey
+type Stats struct {
+	Time       LPFloat        `json:"time"` // Seconds
+	Population int            `json:"population"`
+	Archetypes map[string]int `json:"archetypes"` // The number of agents of each archetype
+	Births     int            `json:"births"`     // During the most recent step
+	Deaths     int            `json:"deaths"`     // During the most recent step, including prey that were caught
+	Resources  LPFloat        `json:"resources"`  // The amount of resource over all the patches
+}
+
+// Stats summarises the Scenario as it is now
+func (s *Scenario) Stats() Stats {
+	stats := Stats{
+		Time:       LPFloat{Value: s.Time.Seconds(), Digits: 3},
+		Population: s.state.Population(),
+		Archetypes: make(map[string]int),
+		Resources:  LPFloat{Digits: 2},
+	}
+	for _, agent := range s.state.Agents {
+		stats.Archetypes[agent.Archetype()]++
+	}
+	for _, event := range s.state.LifeEvents {
+		switch event.Kind {
+		case Born:
+			stats.Births++
+		case Died:
+			stats.Deaths++
+		}
+	}
+	if s.state.Resources != nil {
+		stats.Resources.Value = s.state.Resources.Total()
+	}
+	return stats
+}
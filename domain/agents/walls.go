@@ -0,0 +1,85 @@
+package agents
+
+import (
+	"tjweldon/archetypal-agents/domain/world"
+	"tjweldon/archetypal-agents/worlds"
+)
+
+// WithWalls attaches a Maze of the given walls to the position space of the Scenario. Agents cannot
+// pass through the walls.
+func WithWalls(walls ...worlds.Wall) Option {
+	return populate(func(s *Scenario) {
+		s.attachWalls(walls)
+	})
+}
+
+// WithCells attaches a Maze of the given cells to the position space of the Scenario. Agents cannot
+// enter the cells.
+func WithCells(cells ...worlds.Cell) Option {
+	return populate(func(s *Scenario) {
+		s.attachWalls(nil)
+		s.state.Maze.Cells = append(s.state.Maze.Cells, cells...)
+	})
+}
+
+// WithMaze attaches a randomly generated maze of columns x rows cells that covers the world
+func WithMaze(columns, rows int) Option {
+	return populate(func(s *Scenario) {
+		s.attachWalls(worlds.GenerateMaze(s.bounds.Width, s.bounds.Height, columns, rows, s.random))
+	})
+}
+
+// attachWalls adds walls to the Maze of the Scenario, creating the Maze if there is none yet
+func (s *Scenario) attachWalls(walls []worlds.Wall) {
+	if s.state.Maze == nil {
+		s.state.Maze = worlds.NewMaze(s.positions)
+	}
+	s.state.Maze.Walls = append(s.state.Maze.Walls, walls...)
+}
+
+// Walls returns the walls of the Scenario's Maze, if it has one
+func (s *Scenario) Walls() []worlds.Wall {
+	if s.state.Maze == nil {
+		return []worlds.Wall{}
+	}
+	return s.state.Maze.Walls
+}
+
+// Cells returns the cells of the Scenario's Maze, if it has one
+func (s *Scenario) Cells() []worlds.Cell {
+	if s.state.Maze == nil || s.state.Maze.Cells == nil {
+		return []worlds.Cell{}
+	}
+	return s.state.Maze.Cells
+}
+
+// positionsOf copies the positions of all the agents
+func (s State) positionsOf() []*world.Vector {
+	positions := make([]*world.Vector, s.Population())
+	for index, agent := range s.Agents {
+		positions[index] = agent.Position.Copy()
+	}
+	return positions
+}
+
+// blockByWalls undoes the move of any agent whose path from its previous position crosses a wall
+// or the edge of a cell and reflects its velocity in that wall
+func (s *State) blockByWalls(previous []*world.Vector) {
+	if s.Maze == nil {
+		return
+	}
+	for index, agent := range s.Agents {
+		from := previous[index]
+		deltaX, deltaY := s.CoordinateSystem.GeodesicDiff(from.X, agent.Position.X, from.Y, agent.Position.Y)
+		_, wall, crosses := s.Maze.Crossing(from, deltaX, deltaY)
+		if !crosses {
+			continue
+		}
+
+		agent.Position.X, agent.Position.Y = from.X, from.Y
+		normalX, normalY := s.Maze.Normal(wall)
+		normal := s.Velocities.NewVector(normalX, normalY)
+		reflection := normal.Times(-2 * agent.Velocity.Dot(normal))
+		agent.Velocity.Accumulate(agent.Velocity, &reflection)
+	}
+}
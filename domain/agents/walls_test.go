@@ -0,0 +1,58 @@
+package agents
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+	"tjweldon/archetypal-agents/worlds"
+)
+
+func TestScenario_Step_WallsBlockAgents(t *testing.T) {
+	scenario := InitialiseScenario(time.Second, WithWalls(worlds.Wall{X1: 0, Y1: 150, X2: 0, Y2: 250}))
+	agent := scenario.state.Agents[0]
+	agent.Position.X, agent.Position.Y = width-3, 200
+	agent.Velocity.X, agent.Velocity.Y = 5, 1
+
+	scenario.Step()
+
+	x, y := agent.Position.Wrapped()
+	assert.InDelta(t, width-3, x, MaxPrecision, "the agent should not cross the wall on the seam")
+	assert.InDelta(t, 200.0, y, MaxPrecision)
+	assert.InDelta(t, -5.0, agent.Velocity.X, MaxPrecision, "the velocity should be reflected in the wall")
+	assert.InDelta(t, 1.0, agent.Velocity.Y, MaxPrecision)
+}
+
+func TestScenario_Step_CellsBlockAgents(t *testing.T) {
+	scenario := InitialiseScenario(time.Second, WithPopulation(1), WithCells(worlds.Cell{X: 100, Y: 198, Width: 20, Height: 20}))
+	agent := scenario.state.Agents[0]
+	agent.Position.X, agent.Position.Y = 110, 195
+	agent.Velocity.X, agent.Velocity.Y = 1, 5
+
+	scenario.Step()
+
+	x, y := agent.Position.Wrapped()
+	assert.InDelta(t, 110.0, x, MaxPrecision)
+	assert.InDelta(t, 195.0, y, MaxPrecision, "the agent should not enter the cell")
+	assert.InDelta(t, 1.0, agent.Velocity.X, MaxPrecision)
+	assert.InDelta(t, -5.0, agent.Velocity.Y, MaxPrecision, "the velocity should be reflected in the edge of the cell")
+}
+
+func TestScenario_Walls(t *testing.T) {
+	assert.Empty(t, InitialiseScenario(time.Second).Walls())
+	assert.Len(t, InitialiseScenario(time.Second, WithMaze(3, 3)).Walls(), 10)
+}
+
+func TestScenario_Cells(t *testing.T) {
+	cell := worlds.Cell{X: 10, Y: 20, Width: 30, Height: 40}
+	assert.Empty(t, InitialiseScenario(time.Second).Cells())
+
+	scenario := InitialiseScenario(time.Second, WithCells(cell))
+	assert.Equal(t, []worlds.Cell{cell}, scenario.Cells())
+	assert.Empty(t, scenario.Walls())
+
+	snapshot, err := scenario.Snapshot()
+	assert.NoError(t, err)
+	restored, err := Restore(snapshot)
+	assert.NoError(t, err)
+	assert.Equal(t, []worlds.Cell{cell}, restored.Cells())
+}
@@ -0,0 +1,214 @@
+package world
+
+import (
+	"math"
+	"sort"
+)
+
+// grid divides one axis of a CellList into a whole number of cells of equal length. Periodic axes
+// are divided from zero around the circumference. Unbounded axes are divided from the least point
+// to the greatest, so queries beyond them find no cells.
+type grid struct {
+	axis     MetricSpace1D
+	origin   float64 // Where the first cell starts
+	length   float64 // Of a cell
+	count    int     // Number of cells
+	periodic bool
+}
+
+// divide splits an axis into cells at least size long that cover the scalars
+func divide(axis MetricSpace1D, size float64, scalars []float64) grid {
+	if axis.Circumference > 0 {
+		count := int(math.Max(1, math.Floor(axis.Circumference/size)))
+		return grid{axis: axis, length: axis.Circumference / float64(count), count: count, periodic: true}
+	}
+	if len(scalars) == 0 {
+		return grid{axis: axis, length: size, count: 1}
+	}
+
+	least, greatest := math.Inf(1), math.Inf(-1)
+	for _, scalar := range scalars {
+		least, greatest = math.Min(least, scalar), math.Max(greatest, scalar)
+	}
+	// Widely spread points would need many empty cells, so there are no more cells than points
+	length := math.Max(size, (greatest-least)/float64(len(scalars)))
+	return grid{axis: axis, origin: least, length: length, count: int(math.Floor((greatest-least)/length)) + 1}
+}
+
+// bin returns the index of the cell that contains scalar, which is outside [0, count) for scalars
+// beyond the points of an unbounded axis
+func (g grid) bin(scalar float64) int {
+	if !g.periodic {
+		return int(math.Floor((scalar - g.origin) / g.length))
+	}
+	index := int(math.Floor(g.axis.Wrap(scalar) / g.length))
+	if index >= g.count {
+		index = g.count - 1
+	}
+	return index
+}
+
+// span returns the indices of the cells within reach cells of centre, each exactly once
+func (g grid) span(centre, reach int) []int {
+	first, last := centre-reach, centre+reach
+	switch {
+	case !g.periodic:
+		// Only the cells between the least and greatest points exist
+		if first < 0 {
+			first = 0
+		}
+		if last >= g.count {
+			last = g.count - 1
+		}
+	case 2*reach+1 >= g.count:
+		first, last = 0, g.count-1
+	}
+	if first > last {
+		return nil
+	}
+
+	indices := make([]int, 0, last-first+1)
+	for index := first; index <= last; index++ {
+		indices = append(indices, (index%g.count+g.count)%g.count)
+	}
+	return indices
+}
+
+// CellList is a uniform grid spatial index over a set of points in a MetricSpace2D. Points are
+// binned into square cells so that a radius query only has to inspect the cells the radius
+// reaches, which for bounded density makes a query O(1) rather than O(N). On periodic axes the
+// cells wrap around with the space, so queries see points on the far side of a seam.
+//
+// The cells are stored flat: the points of cell c are entries[starts[c]:starts[c+1]], in
+// ascending order.
+type CellList struct {
+	space           *MetricSpace2D
+	points          []*Vector
+	columns, rows   grid
+	starts, entries []int
+}
+
+// NewCellList builds a CellList of the points with cells whose sides are at least size long.
+// The index refers to the points by their position in the slice, it does not follow them if they
+// are subsequently moved.
+func NewCellList(space *MetricSpace2D, size float64, points []*Vector) *CellList {
+	xs, ys := make([]float64, len(points)), make([]float64, len(points))
+	for index, point := range points {
+		xs[index], ys[index] = point.X, point.Y
+	}
+	list := &CellList{
+		space:   space,
+		points:  points,
+		columns: divide(space.XCoord, size, xs),
+		rows:    divide(space.YCoord, size, ys),
+	}
+
+	// Count the points in each cell, then place them after the points of the preceding cells
+	cells := make([]int, len(points))
+	list.starts = make([]int, list.columns.count*list.rows.count+1)
+	for index, point := range points {
+		cells[index] = list.cellOf(point)
+		list.starts[cells[index]+1]++
+	}
+	for cell := 1; cell < len(list.starts); cell++ {
+		list.starts[cell] += list.starts[cell-1]
+	}
+	next := append([]int{}, list.starts[:len(list.starts)-1]...)
+	list.entries = make([]int, len(points))
+	for index, cell := range cells {
+		list.entries[next[cell]] = index
+		next[cell]++
+	}
+	return list
+}
+
+// cellOf returns the index of the cell containing point, which must be within the grid
+func (c *CellList) cellOf(point *Vector) int {
+	return c.rows.bin(point.Y)*c.columns.count + c.columns.bin(point.X)
+}
+
+// WithinRadius returns the indices, in ascending order, of the points strictly within radius of
+// point according to the Metric of the space
+func (c *CellList) WithinRadius(point *Vector, radius float64) []int {
+	columns := c.columns.span(c.columns.bin(point.X), int(math.Ceil(radius/c.columns.length)))
+	rows := c.rows.span(c.rows.bin(point.Y), int(math.Ceil(radius/c.rows.length)))
+
+	// Every point in the cells is a candidate, so they bound the size of the result
+	candidates := 0
+	for _, row := range rows {
+		for _, column := range columns {
+			cell := row*c.columns.count + column
+			candidates += c.starts[cell+1] - c.starts[cell]
+		}
+	}
+	found := make([]int, 0, candidates)
+	for _, row := range rows {
+		for _, column := range columns {
+			cell := row*c.columns.count + column
+			for _, index := range c.entries[c.starts[cell]:c.starts[cell+1]] {
+				if c.space.Metric(point, c.points[index]) < radius {
+					found = append(found, index)
+				}
+			}
+		}
+	}
+	sort.Ints(found)
+	return found
+}
+
+// Neighbours returns the indices, in ascending order, of the points other than points[index] that
+// are strictly within radius of it
+func (c *CellList) Neighbours(index int, radius float64) []int {
+	found := c.WithinRadius(c.points[index], radius)
+	neighbours := found[:0]
+	for _, other := range found {
+		if other != index {
+			neighbours = append(neighbours, other)
+		}
+	}
+	return neighbours
+}
+
+// Nearest returns the index of the point other than points[index] that is nearest to it, and the
+// distance to that point, or -1 and +Inf if there is no other point. The cells are inspected in
+// rings of increasing reach around the point's cell until no uninspected cell can hold a nearer
+// point, so for bounded density a query only inspects the cells around the point.
+func (c *CellList) Nearest(index int) (int, float64) {
+	point := c.points[index]
+	column, row := c.columns.bin(point.X), c.rows.bin(point.Y)
+	length := math.Min(c.columns.length, c.rows.length)
+	inspectedColumns, inspectedRows := make([]bool, c.columns.count), make([]bool, c.rows.count)
+
+	nearest, distance := -1, math.Inf(1)
+	for reach := 0; ; reach++ {
+		columns, rows := c.columns.span(column, reach), c.rows.span(row, reach)
+		for _, r := range rows {
+			for _, col := range columns {
+				if inspectedRows[r] && inspectedColumns[col] {
+					continue
+				}
+				cell := r*c.columns.count + col
+				for _, other := range c.entries[c.starts[cell]:c.starts[cell+1]] {
+					if other == index {
+						continue
+					}
+					if metric := c.space.Metric(point, c.points[other]); metric < distance || (metric == distance && other < nearest) {
+						nearest, distance = other, metric
+					}
+				}
+			}
+		}
+		for _, r := range rows {
+			inspectedRows[r] = true
+		}
+		for _, col := range columns {
+			inspectedColumns[col] = true
+		}
+
+		// Every uninspected cell is more than reach cells away, so its points are at least that far
+		everything := len(columns) == c.columns.count && len(rows) == c.rows.count
+		if everything || distance <= float64(reach)*length {
+			return nearest, distance
+		}
+	}
+}
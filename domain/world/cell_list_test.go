@@ -0,0 +1,111 @@
+package world
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"tjweldon/archetypal-agents/utils"
+)
+
+func randomPoints(space *MetricSpace2D, count int, extent float64) []*Vector {
+	points := make([]*Vector, count)
+	for index := range points {
+		points[index] = space.NewVector(utils.RandFloat(-extent, extent), utils.RandFloat(-extent, extent))
+	}
+	return points
+}
+
+func bruteForce(space *MetricSpace2D, points []*Vector, point *Vector, radius float64) []int {
+	found := make([]int, 0)
+	for index, other := range points {
+		if space.Metric(point, other) < radius {
+			found = append(found, index)
+		}
+	}
+	return found
+}
+
+func TestCellList_WithinRadius_MatchesBruteForce(t *testing.T) {
+	spaces := map[string]*MetricSpace2D{
+		"toroid":    NewEuclideanToroid(100, 50),
+		"plane":     NewEuclideanPlane(),
+		"cylinder":  {XCoord: Circles(100), YCoord: RealLine()},
+		"tiny":      NewEuclideanToroid(3, 3),
+		"irregular": NewEuclideanToroid(97.5, 13.1),
+	}
+	for name, space := range spaces {
+		points := randomPoints(space, 500, 150)
+		list := NewCellList(space, 10, points)
+
+		for _, radius := range []float64{1, 10, 25, 80} {
+			for range [20]any{} {
+				point := space.NewVector(utils.RandFloat(-150, 150), utils.RandFloat(-150, 150))
+				assert.Equal(t, bruteForce(space, points, point, radius), list.WithinRadius(point, radius), name)
+			}
+		}
+	}
+}
+
+func TestCellList_Neighbours_AcrossSeam(t *testing.T) {
+	toroid := NewEuclideanToroid(100, 100)
+	points := []*Vector{toroid.NewVector(1, 50), toroid.NewVector(99, 50), toroid.NewVector(50, 50)}
+	list := NewCellList(toroid, 10, points)
+
+	assert.Equal(t, []int{1}, list.Neighbours(0, 5))
+	assert.Equal(t, []int{0}, list.Neighbours(1, 5))
+	assert.Empty(t, list.Neighbours(2, 5))
+}
+
+func TestCellList_WithinRadius_BeyondUnboundedPoints(t *testing.T) {
+	plane := NewEuclideanPlane()
+	points := []*Vector{plane.NewVector(0, 0), plane.NewVector(1e6, 0)}
+	list := NewCellList(plane, 10, points)
+
+	assert.Empty(t, list.WithinRadius(plane.NewVector(-500, 3e6), 100))
+	assert.Equal(t, []int{1}, list.WithinRadius(plane.NewVector(1e6+50, 0), 100))
+	assert.Equal(t, []int{0, 1}, list.WithinRadius(plane.NewVector(5e5, 0), 6e5))
+}
+
+func TestCellList_Nearest_MatchesBruteForce(t *testing.T) {
+	spaces := map[string]*MetricSpace2D{
+		"toroid":   NewEuclideanToroid(100, 50),
+		"plane":    NewEuclideanPlane(),
+		"cylinder": {XCoord: Circles(100), YCoord: RealLine()},
+		"tiny":     NewEuclideanToroid(3, 3),
+	}
+	for name, space := range spaces {
+		for _, count := range []int{2, 20, 300} {
+			points := randomPoints(space, count, 150)
+			list := NewCellList(space, 10, points)
+
+			for index, point := range points {
+				want := math.Inf(1)
+				for other, candidate := range points {
+					if other != index {
+						want = math.Min(want, space.Metric(point, candidate))
+					}
+				}
+				nearest, distance := list.Nearest(index)
+				assert.Equal(t, want, distance, name)
+				assert.Equal(t, want, space.Metric(point, points[nearest]), name)
+			}
+		}
+	}
+
+	lonely := NewCellList(NewEuclideanPlane(), 10, []*Vector{NewEuclideanPlane().NewVector(0, 0)})
+	nearest, distance := lonely.Nearest(0)
+	assert.Equal(t, -1, nearest)
+	assert.Equal(t, math.Inf(1), distance)
+}
+
+func BenchmarkCellList_Neighbours(b *testing.B) {
+	toroid := NewEuclideanToroid(2000, 2000)
+	points := randomPoints(toroid, 10000, 1000)
+
+	for i := 0; i < b.N; i++ {
+		list := NewCellList(toroid, 50, points)
+		for index := range points {
+			_ = list.Neighbours(index, 50)
+		}
+	}
+}
@@ -57,3 +57,47 @@ func generateTerms(x int, basis [2]*Vector) []*Vector {
 	}
 	return terms
 }
+
+func TestMetricSpace2D_Crossing(t *testing.T) {
+	plane := NewEuclideanPlane()
+	a, b := plane.NewVector(1, -1), plane.NewVector(1, 1)
+
+	fraction, crosses := plane.Crossing(plane.ZeroVector(), 4, 0, a, b)
+	assert.True(t, crosses)
+	assert.InDelta(t, 0.25, fraction, MaxPrecision)
+
+	_, crosses = plane.Crossing(plane.ZeroVector(), 0.5, 0, a, b)
+	assert.False(t, crosses, "the path stops short of the segment")
+
+	_, crosses = plane.Crossing(plane.NewVector(0, 2), 4, 0, a, b)
+	assert.False(t, crosses, "the path passes beyond the end of the segment")
+
+	_, crosses = plane.Crossing(plane.ZeroVector(), 0, 4, a, b)
+	assert.False(t, crosses, "the path is parallel to the segment")
+}
+
+func TestMetricSpace2D_Crossing_AcrossSeam(t *testing.T) {
+	toroid := NewEuclideanToroid(10, 10)
+	a, b := toroid.NewVector(0, 4), toroid.NewVector(0, 6)
+
+	fraction, crosses := toroid.Crossing(toroid.NewVector(9, 5), 2, 0, a, b)
+	assert.True(t, crosses)
+	assert.InDelta(t, 0.5, fraction, MaxPrecision)
+
+	fraction, crosses = toroid.Crossing(toroid.NewVector(1, 5), -2, 0, toroid.NewVector(10, 4), toroid.NewVector(10, 6))
+	assert.True(t, crosses)
+	assert.InDelta(t, 0.5, fraction, MaxPrecision)
+}
+
+func TestMetricSpace2D_Centroid(t *testing.T) {
+	plane := NewEuclideanPlane()
+	centroid := plane.Centroid([]*Vector{plane.NewVector(1, 2), plane.NewVector(3, -4)})
+	assert.InDelta(t, 2.0, centroid.X, MaxPrecision)
+	assert.InDelta(t, -1.0, centroid.Y, MaxPrecision)
+
+	// Points either side of the seam average to the seam, not the middle of the toroid
+	toroid := NewEuclideanToroid(10, 10)
+	centroid = toroid.Centroid([]*Vector{toroid.NewVector(9, 5), toroid.NewVector(1, 5), toroid.NewVector(0, 7)})
+	assert.InDelta(t, 0.0, toroid.XCoord.Metric(centroid.X, 0), MaxPrecision)
+	assert.Less(t, toroid.YCoord.Metric(centroid.Y, 5.6), 0.1)
+}
@@ -0,0 +1,62 @@
+package world
+
+import "fmt"
+
+// The kinds of Topology1D
+const (
+	Line   = "line"   // The real line, see RealLine
+	Circle = "circle" // A circle of some circumference, see Circles
+)
+
+// Topology1D is a declarative description of a MetricSpace1D. A MetricSpace1D is made of functions
+// and cannot be serialised, its Topology1D can be, and rebuilds the same space.
+type Topology1D struct {
+	Kind          string
+	Circumference float64 // Only meaningful for a Circle
+}
+
+// Topology2D is a declarative description of a MetricSpace2D (see Topology1D)
+type Topology2D struct {
+	X, Y Topology1D
+}
+
+// Topology describes the space, periodic spaces are circles and unbounded ones are lines
+func (m MetricSpace1D) Topology() Topology1D {
+	if m.Circumference > 0 {
+		return Topology1D{Kind: Circle, Circumference: m.Circumference}
+	}
+	return Topology1D{Kind: Line}
+}
+
+// Space rebuilds the MetricSpace1D the Topology1D describes
+func (t Topology1D) Space() (MetricSpace1D, error) {
+	switch t.Kind {
+	case Line:
+		return RealLine(), nil
+	case Circle:
+		if t.Circumference <= 0 {
+			return MetricSpace1D{}, fmt.Errorf("circle has non-positive circumference %v", t.Circumference)
+		}
+		return Circles(t.Circumference), nil
+	default:
+		return MetricSpace1D{}, fmt.Errorf("unknown topology %q", t.Kind)
+	}
+}
+
+// Topology describes the space axis by axis
+func (m *MetricSpace2D) Topology() Topology2D {
+	return Topology2D{X: m.XCoord.Topology(), Y: m.YCoord.Topology()}
+}
+
+// Space rebuilds the MetricSpace2D the Topology2D describes
+func (t Topology2D) Space() (*MetricSpace2D, error) {
+	x, err := t.X.Space()
+	if err != nil {
+		return nil, fmt.Errorf("x: %w", err)
+	}
+	y, err := t.Y.Space()
+	if err != nil {
+		return nil, fmt.Errorf("y: %w", err)
+	}
+	return &MetricSpace2D{XCoord: x, YCoord: y}, nil
+}
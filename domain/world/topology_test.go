@@ -0,0 +1,35 @@
+package world
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"tjweldon/archetypal-agents/utils"
+)
+
+func TestTopology2D_RebuildsSpace(t *testing.T) {
+	spaces := []*MetricSpace2D{
+		NewEuclideanPlane(),
+		NewEuclideanToroid(800, 400),
+		{XCoord: Circles(10), YCoord: RealLine()},
+	}
+	for _, space := range spaces {
+		rebuilt, err := space.Topology().Space()
+		assert.NoError(t, err)
+		assert.Equal(t, space.Topology(), rebuilt.Topology())
+
+		for range [100]any{} {
+			a := space.NewVector(utils.RandFloat(-1000, 1000), utils.RandFloat(-1000, 1000))
+			b := space.NewVector(utils.RandFloat(-1000, 1000), utils.RandFloat(-1000, 1000))
+			assert.Equal(t, space.Metric(a, b), rebuilt.Metric(a, b))
+			sum, rebuiltSum := space.ZeroVector().Accumulate(a, b), rebuilt.ZeroVector().Accumulate(a, b)
+			assert.Equal(t, []float64{sum.X, sum.Y}, []float64{rebuiltSum.X, rebuiltSum.Y})
+		}
+	}
+}
+
+func TestTopology1D_Space_RejectsInvalid(t *testing.T) {
+	for _, topology := range []Topology1D{{Kind: "sphere"}, {Kind: Circle}, {Kind: Circle, Circumference: -1}} {
+		_, err := topology.Space()
+		assert.Error(t, err)
+	}
+}
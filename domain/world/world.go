@@ -25,12 +25,23 @@ type Metric func(scalarA, scalarB float64) float64
 //  - Invert^2 === Identity: 		Invert(Invert(a)) == a
 type Invert func(scalar float64) float64
 
+// Wrap maps a scalar onto its canonical representative in the space. For periodic spaces this
+// is the representative in [0, circumference), which is what should be shown to a client.
+// An implementation should have the following properties:
+//  - Idempotence:			Wrap(Wrap(a)) == Wrap(a)
+//  - Preserves position:	Metric(Wrap(a), a) == 0
+type Wrap func(scalar float64) float64
+
 // MetricSpace1D is a representation of the way distance is calculated in a given coordinate.
 // It contains no state, it is an attribute of the environment in which the simulation takes place.
 type MetricSpace1D struct {
 	Sum    Sum
 	Metric Metric
 	Invert Invert
+	Wrap   Wrap
+
+	// Circumference is the period of a periodic space, it is zero if the space is unbounded
+	Circumference float64
 }
 
 // RealLine returns a MetricSpace1D that behaves like the usual real numbers unbounded above and below
@@ -49,6 +60,9 @@ func RealLine() MetricSpace1D {
 		Invert: func(scalar float64) float64 {
 			return -scalar
 		},
+		Wrap: func(scalar float64) float64 {
+			return scalar
+		},
 	}
 }
 
@@ -71,7 +85,37 @@ func Circles(circumference float64) MetricSpace1D {
 		Invert: func(scalar float64) float64 {
 			return circumference - math.Remainder(scalar, circumference)
 		},
+		Wrap: func(scalar float64) float64 {
+			result := math.Mod(scalar, circumference)
+			if result < 0 {
+				result += circumference
+			}
+			// Adding the circumference to a tiny negative remainder can round up to it
+			if result >= circumference {
+				result = 0
+			}
+			return result
+		},
+		Circumference: circumference,
+	}
+}
+
+// Mean returns the mean of the scalars. On a periodic space it is the circular mean, the point on
+// the circle in the direction of the mean of the scalars as points on a unit circle, so that
+// scalars either side of the seam average to a point on the seam rather than halfway round.
+func (m MetricSpace1D) Mean(scalars []float64) float64 {
+	if len(scalars) == 0 {
+		return 0
+	}
+	if m.Circumference <= 0 {
+		return RealLine().Sum(scalars...) / float64(len(scalars))
+	}
+	cosines, sines := 0.0, 0.0
+	for _, scalar := range scalars {
+		angle := 2 * math.Pi * scalar / m.Circumference
+		cosines, sines = cosines+math.Cos(angle), sines+math.Sin(angle)
 	}
+	return m.Wrap(math.Atan2(sines, cosines) * m.Circumference / (2 * math.Pi))
 }
 
 // MetricSpace2D is a cartesian product of two MetricSpace1D
@@ -91,8 +135,42 @@ func (m MetricSpace2D) GeodesicDiff(x1, x2, y1, y2 float64) (deltaX, deltaY floa
 // Metric is the function that defines the distance between two points represented by the Vector
 // pair (v1, v2)
 func (m *MetricSpace2D) Metric(v1, v2 *Vector) float64 {
-	deltaX, deltaY := m.GeodesicDiff(v1.X, v2.X, v1.Y, v2.Y)
-	return math.Sqrt(math.Pow(deltaX, 2) + math.Pow(deltaY, 2))
+	// The geodesic distance along each axis is the magnitude of the geodesic difference, but is
+	// cheaper to compute. This is the innermost loop of every neighbour query.
+	deltaX, deltaY := m.XCoord.Metric(v1.X, v2.X), m.YCoord.Metric(v1.Y, v2.Y)
+	return math.Sqrt(deltaX*deltaX + deltaY*deltaY)
+}
+
+// Centroid returns the mean of the points, taken along each axis with MetricSpace1D.Mean
+func (m *MetricSpace2D) Centroid(points []*Vector) *Vector {
+	xs, ys := make([]float64, len(points)), make([]float64, len(points))
+	for index, point := range points {
+		xs[index], ys[index] = point.X, point.Y
+	}
+	return m.NewVector(m.XCoord.Mean(xs), m.YCoord.Mean(ys))
+}
+
+// Crossing finds where a path crosses the straight segment between a and b. The path starts at
+// origin and is displaced by (deltaX, deltaY). The result is the fraction of the path travelled
+// before the crossing, and whether it crosses at all.
+//
+// The crossing is found in the frame of the origin using the image of the segment whose midpoint
+// is geodesically nearest, so the result is correct across the seams of a periodic topology
+// provided the segment and path are together shorter than the circumference.
+func (m *MetricSpace2D) Crossing(origin *Vector, deltaX, deltaY float64, a, b *Vector) (fraction float64, crosses bool) {
+	// Direction of the segment and the offset of its midpoint relative to the origin
+	edgeX, edgeY := m.GeodesicDiff(a.X, b.X, a.Y, b.Y)
+	midX, midY := m.GeodesicDiff(origin.X, a.X+edgeX/2, origin.Y, a.Y+edgeY/2)
+	startX, startY := midX-edgeX/2, midY-edgeY/2
+
+	cross := func(ux, uy, vx, vy float64) float64 { return ux*vy - uy*vx }
+	denominator := cross(deltaX, deltaY, edgeX, edgeY)
+	if denominator == 0 {
+		return 0, false
+	}
+	fraction = cross(startX, startY, edgeX, edgeY) / denominator
+	along := cross(startX, startY, deltaX, deltaY) / denominator
+	return fraction, fraction >= 0 && fraction <= 1 && along >= 0 && along <= 1
 }
 
 // NewEuclideanPlane initialises a MetricSpace2D that represents euclidean geometry and non-periodic
@@ -144,6 +222,17 @@ func (v *Vector) Accumulate(vectors ...*Vector) *Vector {
 	return v
 }
 
+// Copy returns a new Vector in the same metric space with the same coordinates
+func (v *Vector) Copy() *Vector {
+	return &Vector{metricSpace: v.metricSpace, X: v.X, Y: v.Y}
+}
+
+// Wrapped returns the canonical representative of the coordinates of the vector (see Wrap).
+// This is the form in which a position should be presented outside the simulation.
+func (v *Vector) Wrapped() (x, y float64) {
+	return v.metricSpace.XCoord.Wrap(v.X), v.metricSpace.YCoord.Wrap(v.Y)
+}
+
 // Times is an **immutable** scalar multiple of the vector instance where the semantics are:
 // u = v.Times(x) => u != v
 func (v *Vector) Times(scalar float64) Vector {
@@ -180,6 +269,25 @@ func (v *Vector) Mag() float64 {
 	return v.metricSpace.Metric(v, v.metricSpace.ZeroVector())
 }
 
+// SetMag is a **mutating** rescaling of the vector to the given magnitude. The zero vector has no
+// direction and is left unchanged.
+func (v *Vector) SetMag(magnitude float64) *Vector {
+	current := v.Mag()
+	if current == 0 {
+		return v
+	}
+	return v.Scale(magnitude / current)
+}
+
+// Limit is a **mutating** operation that rescales the vector to the given magnitude only if it is
+// currently longer than that.
+func (v *Vector) Limit(magnitude float64) *Vector {
+	if v.Mag() > magnitude {
+		return v.SetMag(magnitude)
+	}
+	return v
+}
+
 // Dot is the scalar product of two vectors with the following semantics:
 // x = u ⋅ v <=> x := u.Dot(v)
 //
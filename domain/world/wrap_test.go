@@ -0,0 +1,54 @@
+package world
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"tjweldon/archetypal-agents/utils"
+)
+
+func TestLine_Wrap(t *testing.T) {
+	space := RealLine()
+	wrapIsIdempotent(t, space)
+	wrapPreservesPosition(t, space)
+}
+
+func TestCircles_Wrap(t *testing.T) {
+	spaces := [3]MetricSpace1D{
+		Circles(1), Circles(10), Circles(100),
+	}
+	circumferences := [3]float64{1, 10, 100}
+	for index, circle := range spaces {
+		wrapIsIdempotent(t, circle)
+		wrapPreservesPosition(t, circle)
+		wrapIsWithinCircumference(t, circle, circumferences[index])
+	}
+}
+
+func wrapIsIdempotent(t *testing.T, space MetricSpace1D) {
+	assertAdjacent := getAdjacencyAssertion(t, space)
+
+	var a float64
+	for range [100]any{} {
+		a = utils.RandFloat(-1000, 1000)
+		assertAdjacent(space.Wrap(space.Wrap(a)), space.Wrap(a))
+	}
+}
+
+func wrapPreservesPosition(t *testing.T, space MetricSpace1D) {
+	assertAdjacent := getAdjacencyAssertion(t, space)
+
+	var a float64
+	for range [100]any{} {
+		a = utils.RandFloat(-1000, 1000)
+		assertAdjacent(space.Wrap(a), a)
+	}
+}
+
+func wrapIsWithinCircumference(t *testing.T, space MetricSpace1D, circumference float64) {
+	var a float64
+	for range [100]any{} {
+		a = utils.RandFloat(-1000, 1000)
+		assert.GreaterOrEqual(t, space.Wrap(a), 0.0)
+		assert.Less(t, space.Wrap(a), circumference)
+	}
+}
@@ -0,0 +1,45 @@
+package graphs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// dotAttributes formats the values of the attributes item has as a DOT attribute list. Strings are
+// quoted, numbers are written bare.
+func dotAttributes(attributes []attribute, item any) string {
+	values := make([]string, 0, len(attributes))
+	for _, attribute := range attributes {
+		value, ok := attribute.value(item)
+		if !ok {
+			continue
+		}
+		if attribute.kind == "string" {
+			value = strconv.Quote(value)
+		}
+		values = append(values, attribute.name+"="+value)
+	}
+	return strings.Join(values, ", ")
+}
+
+// WriteDOT writes the Graph in the Graphviz DOT language, as a digraph if it is Directed. Nodes are
+// identified by the IDs of their agents.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	buffered := bufio.NewWriter(w)
+	kind, connector := "graph", "--"
+	if g.Directed {
+		kind, connector = "digraph", "->"
+	}
+	fmt.Fprintf(buffered, "%s %s {\n", kind, g.Links)
+	for _, node := range g.Nodes {
+		fmt.Fprintf(buffered, "  %d [%s];\n", node.ID, dotAttributes(nodeAttributes, node))
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(buffered, "  %d %s %d [%s];\n", edge.Source, connector, edge.Target, dotAttributes(edgeAttributes, edge))
+	}
+	fmt.Fprintln(buffered, "}")
+	return buffered.Flush()
+}
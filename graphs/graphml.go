@@ -0,0 +1,106 @@
+package graphs
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+// graphML is the root element of a GraphML document
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+// graphMLKey declares an attribute of the nodes or edges
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// data returns the values of the attributes item has, keyed by the IDs of their declarations
+func data(attributes []attribute, prefix string, item any) []graphMLData {
+	values := make([]graphMLData, 0, len(attributes))
+	for _, attribute := range attributes {
+		if value, ok := attribute.value(item); ok {
+			values = append(values, graphMLData{Key: prefix + attribute.name, Value: value})
+		}
+	}
+	return values
+}
+
+// WriteGraphML writes the Graph as a GraphML document. Nodes are identified by the IDs of their
+// agents, and their attributes are declared as keys prefixed "node_" and edge attributes "edge_".
+func (g *Graph) WriteGraphML(w io.Writer) error {
+	document := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Graph: graphMLGraph{ID: string(g.Links), EdgeDefault: "undirected"},
+	}
+	if g.Directed {
+		document.Graph.EdgeDefault = "directed"
+	}
+	for _, declarations := range []struct {
+		attributes []attribute
+		kind       string
+	}{{nodeAttributes, "node"}, {edgeAttributes, "edge"}} {
+		for _, attribute := range declarations.attributes {
+			document.Keys = append(document.Keys, graphMLKey{
+				ID:   declarations.kind + "_" + attribute.name,
+				For:  declarations.kind,
+				Name: attribute.name,
+				Type: attribute.kind,
+			})
+		}
+	}
+	for _, node := range g.Nodes {
+		document.Graph.Nodes = append(document.Graph.Nodes, graphMLNode{
+			ID:   strconv.Itoa(node.ID),
+			Data: data(nodeAttributes, "node_", node),
+		})
+	}
+	for _, edge := range g.Edges {
+		document.Graph.Edges = append(document.Graph.Edges, graphMLEdge{
+			Source: strconv.Itoa(edge.Source),
+			Target: strconv.Itoa(edge.Target),
+			Data:   data(edgeAttributes, "edge_", edge),
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,168 @@
+// Package graphs exports the interactions between the agents of a State as a graph, for analysis
+// with network tools. The nodes are the agents, with their attributes as node data, and the edges
+// are one kind of interaction: the bonds between agents, the agents each agent perceives, or the
+// agents within a radius of each other. A Graph is written as GraphML or Graphviz DOT.
+package graphs
+
+import (
+	"fmt"
+	"strconv"
+	"tjweldon/archetypal-agents/domain/agents"
+)
+
+// Links is the kind of interaction the edges of a Graph represent
+type Links string
+
+const (
+	Bonds      Links = "bonds"      // An undirected edge for each bond
+	Perception Links = "perception" // A directed edge from each agent to every agent within the radius it perceives
+	Proximity  Links = "proximity"  // An undirected edge between every pair of agents within the radius of each other
+)
+
+// Node is an agent and its attributes
+type Node struct {
+	ID        int
+	Archetype string
+	X, Y      float64 // Wrapped onto the canonical representative of the position
+	Heading   float64
+	Speed     float64
+	Age       float64
+	Energy    *float64 // Nil if the agent has no Metabolism
+	Radius    *float64 // Nil if the agent has no Body
+	Territory int      // ID of the Territory the agent is in, zero if none
+	Group     int      // ID of the cluster the agent is in, zero if none
+}
+
+// Edge is an interaction between the agents with IDs Source and Target
+type Edge struct {
+	Source, Target int
+	Distance       float64 // Between the agents
+	Bond           *agents.BondSpec
+}
+
+// Graph is the interaction graph of a State, its nodes are in the order of State.Agents
+type Graph struct {
+	Links    Links
+	Directed bool
+	Nodes    []Node
+	Edges    []Edge
+}
+
+// Build builds the graph of the interactions of the given kind between the agents of the State.
+// The radius bounds Perception and Proximity links, it is not used for Bonds.
+func Build(state *agents.State, links Links, radius float64) (*Graph, error) {
+	graph := &Graph{Links: links, Nodes: make([]Node, state.Population()), Edges: make([]Edge, 0)}
+	frame := state.Frame()
+	for index, agent := range state.Agents {
+		coords := frame[index]
+		node := Node{
+			ID:        agent.ID,
+			Archetype: agent.Archetype(),
+			X:         coords.X.Value,
+			Y:         coords.Y.Value,
+			Heading:   agent.Heading,
+			Speed:     agent.Velocity.Mag(),
+			Age:       agent.Age,
+			Territory: coords.Territory,
+			Group:     agent.Group,
+		}
+		if agent.Metabolism != nil {
+			energy := agent.Metabolism.Energy
+			node.Energy = &energy
+		}
+		if agent.Body != nil {
+			bodyRadius := agent.Body.Radius
+			node.Radius = &bodyRadius
+		}
+		graph.Nodes[index] = node
+	}
+
+	switch links {
+	case Bonds:
+		for _, bond := range state.Bonds {
+			spec := bond.BondSpec
+			graph.Edges = append(graph.Edges, Edge{
+				Source:   bond.A.ID,
+				Target:   bond.B.ID,
+				Distance: state.CoordinateSystem.Metric(bond.A.Position, bond.B.Position),
+				Bond:     &spec,
+			})
+		}
+	case Perception:
+		graph.Directed = true
+		for index, agent := range state.Agents {
+			for _, neighbour := range state.NewView(index).Neighbours(radius) {
+				graph.Edges = append(graph.Edges, Edge{Source: agent.ID, Target: neighbour.Agent.ID, Distance: neighbour.Distance})
+			}
+		}
+	case Proximity:
+		for index, agent := range state.Agents {
+			for _, other := range state.Neighbours(index, radius) {
+				if other < index {
+					continue
+				}
+				graph.Edges = append(graph.Edges, Edge{
+					Source:   agent.ID,
+					Target:   state.Agents[other].ID,
+					Distance: state.CoordinateSystem.Metric(agent.Position, state.Agents[other].Position),
+				})
+			}
+		}
+	default:
+		return nil, fmt.Errorf("links must be %q, %q or %q, not %q", Bonds, Perception, Proximity, links)
+	}
+	return graph, nil
+}
+
+// attribute is a datum of the nodes or edges of a Graph as it is written. Kind is its GraphML type,
+// and value formats it for a node or edge, or reports that the node or edge has none.
+type attribute struct {
+	name, kind string
+	value      func(item any) (string, bool)
+}
+
+// number formats a float attribute to a fixed number of decimal places
+func number(value float64, digits int) (string, bool) {
+	return strconv.FormatFloat(value, 'f', digits, 64), true
+}
+
+// nodeAttributes are the attributes of every Node, in the order they are written
+var nodeAttributes = []attribute{
+	{"archetype", "string", func(item any) (string, bool) { return item.(Node).Archetype, true }},
+	{"x", "double", func(item any) (string, bool) { return number(item.(Node).X, 2) }},
+	{"y", "double", func(item any) (string, bool) { return number(item.(Node).Y, 2) }},
+	{"heading", "double", func(item any) (string, bool) { return number(item.(Node).Heading, 3) }},
+	{"speed", "double", func(item any) (string, bool) { return number(item.(Node).Speed, 3) }},
+	{"age", "double", func(item any) (string, bool) { return number(item.(Node).Age, 3) }},
+	{"energy", "double", func(item any) (string, bool) {
+		if energy := item.(Node).Energy; energy != nil {
+			return number(*energy, 2)
+		}
+		return "", false
+	}},
+	{"radius", "double", func(item any) (string, bool) {
+		if radius := item.(Node).Radius; radius != nil {
+			return number(*radius, 2)
+		}
+		return "", false
+	}},
+	{"territory", "int", func(item any) (string, bool) { return strconv.Itoa(item.(Node).Territory), true }},
+	{"group", "int", func(item any) (string, bool) { return strconv.Itoa(item.(Node).Group), true }},
+}
+
+// edgeAttributes are the attributes of every Edge, in the order they are written
+var edgeAttributes = []attribute{
+	{"distance", "double", func(item any) (string, bool) { return number(item.(Edge).Distance, 2) }},
+	{"restLength", "double", func(item any) (string, bool) {
+		if bond := item.(Edge).Bond; bond != nil {
+			return number(bond.RestLength, 2)
+		}
+		return "", false
+	}},
+	{"stiffness", "double", func(item any) (string, bool) {
+		if bond := item.(Edge).Bond; bond != nil {
+			return number(bond.Stiffness, 3)
+		}
+		return "", false
+	}},
+}
@@ -0,0 +1,102 @@
+package graphs
+
+import (
+	"bytes"
+	"encoding/xml"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"strings"
+	"testing"
+	"tjweldon/archetypal-agents/domain/agents"
+	"tjweldon/archetypal-agents/domain/world"
+)
+
+// society is three agents in a row across the seam of an 800 x 400 toroid, 10 apart, with a bond
+// between the outer two. The first has a Metabolism and faces away from the others.
+func society() *agents.State {
+	toroid, plane := world.NewEuclideanToroid(800, 400), world.NewEuclideanPlane()
+	state := agents.NewState(toroid, plane)
+	for _, x := range []float64{790, 0, 10} {
+		state.Add(&agents.Agent{Position: toroid.NewVector(x, 200), Velocity: plane.NewVector(3, 4)})
+	}
+	state.Agents[0].Metabolism = &agents.Metabolism{Energy: 12.5}
+	state.Agents[0].Heading = math.Pi
+	state.Agents[0].Perception = &agents.Perception{FieldOfView: math.Pi}
+	state.FormBond(state.Agents[0], state.Agents[2], agents.BondSpec{RestLength: 15, Stiffness: 2})
+	return state
+}
+
+// edges returns the source and target of every edge of the graph
+func edges(graph *Graph) [][2]int {
+	pairs := make([][2]int, len(graph.Edges))
+	for index, edge := range graph.Edges {
+		pairs[index] = [2]int{edge.Source, edge.Target}
+	}
+	return pairs
+}
+
+func TestBuild_Nodes(t *testing.T) {
+	graph, err := Build(society(), Bonds, 0)
+	assert.NoError(t, err)
+
+	assert.Len(t, graph.Nodes, 3)
+	node := graph.Nodes[0]
+	assert.Equal(t, 1, node.ID)
+	assert.Equal(t, "agent", node.Archetype)
+	assert.Equal(t, 790.0, node.X)
+	assert.InDelta(t, 5.0, node.Speed, 1e-9)
+	assert.Equal(t, 12.5, *node.Energy)
+	assert.Nil(t, graph.Nodes[1].Energy)
+}
+
+func TestBuild_Links(t *testing.T) {
+	bonds, err := Build(society(), Bonds, 0)
+	assert.NoError(t, err)
+	assert.False(t, bonds.Directed)
+	assert.Equal(t, [][2]int{{1, 3}}, edges(bonds))
+	assert.InDelta(t, 20.0, bonds.Edges[0].Distance, 1e-9, "the distance is measured across the seam")
+	assert.Equal(t, 15.0, bonds.Edges[0].Bond.RestLength)
+
+	proximity, err := Build(society(), Proximity, 15)
+	assert.NoError(t, err)
+	assert.Equal(t, [][2]int{{1, 2}, {2, 3}}, edges(proximity))
+
+	perception, err := Build(society(), Perception, 15)
+	assert.NoError(t, err)
+	assert.True(t, perception.Directed)
+	assert.Equal(t, [][2]int{{2, 1}, {2, 3}, {3, 2}}, edges(perception), "the first agent faces away from the others")
+
+	_, err = Build(society(), "friendship", 15)
+	assert.Error(t, err)
+}
+
+func TestGraph_WriteGraphML(t *testing.T) {
+	graph, _ := Build(society(), Bonds, 0)
+	var written bytes.Buffer
+	assert.NoError(t, graph.WriteGraphML(&written))
+
+	var document graphML
+	assert.NoError(t, xml.Unmarshal(written.Bytes(), &document))
+	assert.Equal(t, "undirected", document.Graph.EdgeDefault)
+	assert.Len(t, document.Keys, len(nodeAttributes)+len(edgeAttributes))
+	assert.Len(t, document.Graph.Nodes, 3)
+	assert.Contains(t, document.Graph.Nodes[0].Data, graphMLData{Key: "node_energy", Value: "12.50"})
+	assert.Len(t, document.Graph.Nodes[1].Data, len(nodeAttributes)-2, "the agent has no energy or radius")
+	assert.Equal(t, graphMLEdge{Source: "1", Target: "3", Data: []graphMLData{
+		{Key: "edge_distance", Value: "20.00"},
+		{Key: "edge_restLength", Value: "15.00"},
+		{Key: "edge_stiffness", Value: "2.000"},
+	}}, document.Graph.Edges[0])
+}
+
+func TestGraph_WriteDOT(t *testing.T) {
+	graph, _ := Build(society(), Perception, 15)
+	var written strings.Builder
+	assert.NoError(t, graph.WriteDOT(&written))
+
+	lines := strings.Split(strings.TrimSpace(written.String()), "\n")
+	assert.Equal(t, "digraph perception {", lines[0])
+	assert.Equal(t, `  1 [archetype="agent", x=790.00, y=200.00, heading=3.142, speed=5.000, age=0.000, energy=12.50, territory=0, group=0];`, lines[1])
+	assert.Equal(t, "  2 -> 1 [distance=10.00];", lines[4])
+	assert.Equal(t, "}", lines[len(lines)-1])
+}
@@ -1 +0,0 @@
-browser-sync start --server -f -w
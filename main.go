@@ -15,18 +15,116 @@ import (
 	"strconv"
 	"time"
 	"tjweldon/archetypal-agents/domain/agents"
+	"tjweldon/archetypal-agents/graphs"
+	"tjweldon/archetypal-agents/scenarios"
+	"tjweldon/archetypal-agents/worlds"
 )
 
 var addr = flag.String("addr", "localhost:8080", "http service address")
+var scenarioPath = flag.String("scenario", "", "path of a JSON scenario file, the headline scenario if empty")
+
+// scenario is the scenario file given on the command line, nil for the headline scenario
+var scenario *scenarios.Scenario
 
 var upgrader = websocket.Upgrader{} // use default options
 
+// The resolution of the grid the flow field is sampled on for the client
+const flowColumns, flowRows = 32, 16
+
+// The latest time in seconds a graph can be exported at, each export replays the run from the start
+const maxGraphTime = 600
+
+// exportGraph writes the interaction graph of the scenario at a time as
+// GraphML or DOT. The query gives
+//  - seed: the run to export, as sent over the socket, required
+//  - time: in seconds up to maxGraphTime, the default is the start of the run
+//  - links: bonds, perception or proximity, the default is bonds
+//  - radius: the reach of perception and proximity links, the default is 50
+//  - format: graphml or dot, the default is graphml
+func exportGraph(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	seed, err := strconv.ParseInt(query.Get("seed"), 10, 64)
+	if err != nil {
+		http.Error(w, "seed must be the integer seed of a run", http.StatusBadRequest)
+		return
+	}
+	number := func(name string, fallback float64) (float64, bool) {
+		text := query.Get(name)
+		if text == "" {
+			return fallback, true
+		}
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil || value < 0 {
+			http.Error(w, name+" must be a non-negative number", http.StatusBadRequest)
+			return 0, false
+		}
+		return value, true
+	}
+	t, ok := number("time", 0)
+	if !ok {
+		return
+	}
+	if t > maxGraphTime {
+		http.Error(w, "time must be at most "+strconv.Itoa(maxGraphTime)+" seconds", http.StatusBadRequest)
+		return
+	}
+	radius, ok := number("radius", 50)
+	if !ok {
+		return
+	}
+	links := graphs.Links(query.Get("links"))
+	if links == "" {
+		links = graphs.Bonds
+	}
+	format := query.Get("format")
+	if format != "" && format != "graphml" && format != "dot" {
+		http.Error(w, "format must be graphml or dot", http.StatusBadRequest)
+		return
+	}
+
+	simulation := newScenario(agents.WithSeed(seed))
+	target := time.Duration(t * float64(time.Second))
+	for simulation.Time < target {
+		if r.Context().Err() != nil {
+			// The client has gone, there is no one to export the graph to
+			return
+		}
+		simulation.Step()
+	}
+	graph, err := graphs.Build(simulation.State(), links, radius)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if format == "dot" {
+		w.Header().Set("Content-Type", "text/vnd.graphviz")
+		err = graph.WriteDOT(w)
+	} else {
+		w.Header().Set("Content-Type", "application/graphml+xml")
+		err = graph.WriteGraphML(w)
+	}
+	if err != nil {
+		log.Print("graph:", err)
+	}
+}
+
 // streamFrames handles the websocket that will stream the animation frames.
 // It sets up:
 //  - The listen goroutine to handle buffering requests from the socket client.
 //  - The frameGenerator goroutine to generate the requested number of frames.
 //  - A loop to serialise and return contiguous chunks of frame data to the client.
 func streamFrames(w http.ResponseWriter, r *http.Request) {
+	// An optional seed recreates an earlier run exactly
+	var options []agents.Option
+	if seed := r.URL.Query().Get("seed"); seed != "" {
+		value, err := strconv.ParseInt(seed, 10, 64)
+		if err != nil {
+			http.Error(w, "seed must be an integer", http.StatusBadRequest)
+			return
+		}
+		options = append(options, agents.WithSeed(value))
+	}
+
 	// Upgrade the web request to a socket
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
@@ -44,10 +142,26 @@ func streamFrames(w http.ResponseWriter, r *http.Request) {
 
 	// Channel setup
 	frameStream := make(chan []agents.Frame)
+	statsStream := make(chan []agents.Stats, 1)
+	orderStream := make(chan []agents.Order, 1)
+	flowStream := make(chan []worlds.FlowSample, 1)
+	patchStream := make(chan []worlds.Patch, 1)
 	frameRequest := make(chan int)
 
+	// The seed and static geometry are sent once, before any frames
+	simulation := newScenario(options...)
+	if err := send(conn, "seed", simulation.Seed); err != nil {
+		return
+	}
+	if err := send(conn, "walls", simulation.Walls()); err != nil {
+		return
+	}
+	if err := send(conn, "cells", simulation.Cells()); err != nil {
+		return
+	}
+
 	// Frame data calculation goroutine
-	go frameGenerator(frameStream, frameRequest)
+	go frameGenerator(simulation, frameStream, statsStream, orderStream, flowStream, patchStream, frameRequest)
 
 	// Listens for buffering requests
 	go listen(conn, frameRequest)
@@ -57,38 +171,106 @@ func streamFrames(w http.ResponseWriter, r *http.Request) {
 
 	for {
 		select {
-		case frames := <-frameStream:
-			rawJson, err := json.Marshal(frames)
-			if err != nil {
+		case frames, ok := <-frameStream:
+			if !ok {
 				return
 			}
-			err = conn.WriteMessage(websocket.TextMessage, rawJson)
-			if err != nil {
+			if err := send(conn, "frames", frames); err != nil {
+				return
+			}
+			// The stats of each of the frames, for charting
+			if err := send(conn, "stats", <-statsStream); err != nil {
+				return
+			}
+			// The order parameters of each of the frames
+			if err := send(conn, "order", <-orderStream); err != nil {
+				return
+			}
+			// The flow at the end of the frames, for drawing flow lines
+			if err := send(conn, "flow", <-flowStream); err != nil {
+				return
+			}
+			// The resource patches at the end of the frames
+			if err := send(conn, "patches", <-patchStream); err != nil {
 				return
 			}
 		}
 	}
 }
 
+// message is the envelope for everything sent over the socket, the Type
+// tells the client how to interpret the Data.
+type message struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+// send serialises data in a message of the given type and writes it to
+// the socket
+func send(conn *websocket.Conn, messageType string, data any) error {
+	rawJson, err := json.Marshal(message{Type: messageType, Data: data})
+	if err != nil {
+		return err
+	}
+	return conn.WriteMessage(websocket.TextMessage, rawJson)
+}
+
+// newScenario initialises the scenario given on the command line, or else
+// the headline scenario from the design doc: a flock of agents and some
+// lovers trying to bond in a maze that is partitioned into the territories
+// of rulers, with turbulence trying to wash them all away, and the flocks
+// they form picked out by clustering. Further options,
+// such as the seed, are applied after those of the scenario.
+func newScenario(options ...agents.Option) *agents.Scenario {
+	if scenario != nil {
+		return scenario.Initialise(options...)
+	}
+	headline := []agents.Option{
+		agents.WithFlocking(agents.DefaultFlocking()),
+		agents.WithLovers(10, agents.DefaultLover()),
+		agents.WithRulers(3, agents.DefaultRuler()),
+		agents.WithMaze(8, 4),
+		agents.WithTurbulence(6, 5),
+		agents.WithClustering(agents.DefaultClustering()),
+	}
+	return agents.InitialiseScenario(time.Second/60, append(headline, options...)...)
+}
+
 // frameGenerator is intended to be run asynchronously and will await a
 // message on the frameRequest channel in the form of an integer number
 // of frames. On receiving such a message it will calculate the next
-// sequence of frames until it has the number requested. They are then
-// sent into the frameStream channel.
-func frameGenerator(frameStream chan []agents.Frame, frameRequest chan int) {
+// sequence of frames of the simulation until it has the number requested.
+// They are then sent into the frameStream channel, followed by the stats of
+// each frame into the statsStream channel, the order parameters of each
+// frame into the orderStream channel, a sample of the flow field into the
+// flowStream channel and the resource patches into the patchStream channel.
+func frameGenerator(
+	simulation *agents.Scenario,
+	frameStream chan []agents.Frame,
+	statsStream chan []agents.Stats,
+	orderStream chan []agents.Order,
+	flowStream chan []worlds.FlowSample,
+	patchStream chan []worlds.Patch,
+	frameRequest chan int,
+) {
 	defer close(frameStream)
 	frameCount := 0
-	simulation := agents.InitialiseScenario(time.Second / 60)
 	for seqLen := range frameRequest {
 		switch seqLen {
 		case -1:
 			return
 		default:
-			frames := make([]agents.Frame, seqLen)
-			for i := 0; i < frameCount; i++ {
+			frames, stats, order := make([]agents.Frame, seqLen), make([]agents.Stats, seqLen), make([]agents.Order, seqLen)
+			for i := 0; i < seqLen; i++ {
 				frames[i] = simulation.GetNextFrame()
+				stats[i] = simulation.Stats()
+				order[i] = simulation.Order()
 			}
 			frameStream <- frames
+			statsStream <- stats
+			orderStream <- order
+			flowStream <- simulation.FlowSamples(flowColumns, flowRows)
+			patchStream <- simulation.Patches()
 		}
 		frameCount += seqLen
 	}
@@ -124,18 +306,35 @@ func listen(conn *websocket.Conn, frameRequest chan int) {
 
 // index renders the root page to the response
 func index(w http.ResponseWriter, r *http.Request) {
-	otherTemplate.Execute(w, "ws://"+r.Host+"/tick")
+	otherTemplate.Execute(w, socketURL(r))
 }
 
 // debug renders the root page with extra dev info
 func debug(w http.ResponseWriter, r *http.Request) {
-	debugTemplate.Execute(w, "ws://"+r.Host+"/tick")
+	debugTemplate.Execute(w, socketURL(r))
+}
+
+// socketURL is the address of the frame socket, forwarding the query of
+// the page request so that eg. /?seed=42 replays the run with seed 42
+func socketURL(r *http.Request) string {
+	url := "ws://" + r.Host + "/tick"
+	if r.URL.RawQuery != "" {
+		url += "?" + r.URL.RawQuery
+	}
+	return url
 }
 
 func main() {
 	flag.Parse()
 	log.SetFlags(0)
 
+	if *scenarioPath != "" {
+		var err error
+		if scenario, err = scenarios.Load(*scenarioPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	// Pages
 	http.HandleFunc("/", index)
 	http.HandleFunc("/debug", debug)
@@ -143,6 +342,9 @@ func main() {
 	// Sockets
 	http.HandleFunc("/tick", streamFrames)
 
+	// Exports
+	http.HandleFunc("/graph", exportGraph)
+
 	// Static assets (doesn't seem to be necessary)
 	http.Handle("/src/", http.FileServer(http.Dir(".")))
 	http.Handle("/resources/", http.FileServer(http.Dir(".")))
@@ -0,0 +1,771 @@
+package scenarios
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+	"tjweldon/archetypal-agents/domain/agents"
+	"tjweldon/archetypal-agents/domain/world"
+	"tjweldon/archetypal-agents/worlds"
+)
+
+// document is the top level of a scenario file. Objects nested within it are kept raw and decoded
+// separately, over their defaults and with their path, so that problems with them can be located.
+type document struct {
+	World      json.RawMessage   `json:"world"`
+	TimeStep   float64           `json:"timeStep"` // Seconds
+	Seed       *int64            `json:"seed"`
+	MaxSpeed   float64           `json:"maxSpeed"` // Default for the speeds of every group
+	Population []json.RawMessage `json:"population"`
+	Flocking   json.RawMessage   `json:"flocking"`   // The boids rules followed by plain agents
+	Maze       json.RawMessage   `json:"maze"`       // A random maze over the world
+	Cells      []json.RawMessage `json:"cells"`      // Impassable rectangles
+	Turbulence json.RawMessage   `json:"turbulence"` // A turbulent flow over the world
+	Resources  json.RawMessage   `json:"resources"`  // Resource patches scattered over the world
+	Metabolism json.RawMessage   `json:"metabolism"` // The energy budget of the agents, or a list of them
+	Foraging   json.RawMessage   `json:"foraging"`   // How the agents look for resources, or a list of ways
+	Lifecycle  json.RawMessage   `json:"lifecycle"`  // How the agents age and reproduce, or a list of lifecycles
+	Perception json.RawMessage   `json:"perception"` // What the agents can sense, or a list of perceptions
+	Bodies     json.RawMessage   `json:"bodies"`     // The extent and mass of the agents, or a list of them
+	Turning    json.RawMessage   `json:"turning"`    // How fast the agents can turn, or a list of turn rates
+	Collisions json.RawMessage   `json:"collisions"` // How agents with bodies collide
+	Clustering json.RawMessage   `json:"clustering"` // How the agents are grouped into clusters
+}
+
+type worldDocument struct {
+	Width    float64         `json:"width"`
+	Height   float64         `json:"height"`
+	Topology json.RawMessage `json:"topology"`
+}
+
+// topologyDocument names the kind of each axis, world.Line or world.Circle. A circle's
+// circumference is the size of the world along that axis.
+type topologyDocument struct {
+	X string `json:"x"`
+	Y string `json:"y"`
+}
+
+type groupDocument struct {
+	Archetype string            `json:"archetype"` // agent, lover, ruler, predator or prey
+	Count     int               `json:"count"`
+	Spawn     []json.RawMessage `json:"spawn"`
+	Lover     json.RawMessage   `json:"lover"`    // Parameters of a lover group
+	Ruler     json.RawMessage   `json:"ruler"`    // Parameters of a ruler group
+	Predator  json.RawMessage   `json:"predator"` // Parameters of a predator group
+	Prey      json.RawMessage   `json:"prey"`     // Parameters of a prey group
+}
+
+type flockingDocument struct {
+	PerceptionRadius float64 `json:"perceptionRadius"`
+	MaxSpeed         float64 `json:"maxSpeed"`
+	MaxForce         float64 `json:"maxForce"`
+	Separation       float64 `json:"separation"`
+	Cohesion         float64 `json:"cohesion"`
+	Alignment        float64 `json:"alignment"`
+}
+
+type bondDocument struct {
+	RestLength     float64 `json:"restLength"`
+	Stiffness      float64 `json:"stiffness"`
+	BreakingStrain float64 `json:"breakingStrain"`
+}
+
+type loverDocument struct {
+	Desire           float64         `json:"desire"`
+	Persistence      float64         `json:"persistence"`
+	PerceptionRadius float64         `json:"perceptionRadius"`
+	BondDistance     float64         `json:"bondDistance"`
+	BondTime         float64         `json:"bondTime"`
+	Bond             json.RawMessage `json:"bond"`
+	MaxSpeed         float64         `json:"maxSpeed"`
+}
+
+type rulerDocument struct {
+	Establish        float64 `json:"establish"`
+	MinRadius        float64 `json:"minRadius"`
+	MaxRadius        float64 `json:"maxRadius"`
+	Growth           float64 `json:"growth"`
+	Decay            float64 `json:"decay"`
+	Reach            float64 `json:"reach"`
+	PerceptionRadius float64 `json:"perceptionRadius"`
+	Subjugate        bool    `json:"subjugate"`
+	MaxSpeed         float64 `json:"maxSpeed"`
+}
+
+type predatorDocument struct {
+	PerceptionRadius float64 `json:"perceptionRadius"`
+	Reach            float64 `json:"reach"`
+	Nourishment      float64 `json:"nourishment"`
+	Lead             float64 `json:"lead"`
+	MaxSpeed         float64 `json:"maxSpeed"`
+}
+
+type preyDocument struct {
+	PerceptionRadius float64 `json:"perceptionRadius"`
+	Fear             float64 `json:"fear"`
+	MaxSpeed         float64 `json:"maxSpeed"`
+}
+
+type mazeDocument struct {
+	Columns int `json:"columns"`
+	Rows    int `json:"rows"`
+}
+
+type cellDocument struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+type turbulenceDocument struct {
+	Modes    int     `json:"modes"`
+	Strength float64 `json:"strength"`
+}
+
+type resourcesDocument struct {
+	Count    int     `json:"count"`
+	Radius   float64 `json:"radius"`
+	Capacity float64 `json:"capacity"`
+	Regrowth float64 `json:"regrowth"` // Per second
+}
+
+// archetypesDocument restricts a document to agents of the listed archetypes, all of them if empty
+type archetypesDocument struct {
+	Archetypes []string `json:"archetypes"`
+}
+
+type metabolismDocument struct {
+	Energy    float64 `json:"energy"`
+	Capacity  float64 `json:"capacity"`
+	Basal     float64 `json:"basal"`
+	Movement  float64 `json:"movement"`
+	Appetite  float64 `json:"appetite"`
+	Hibernate bool    `json:"hibernate"`
+	archetypesDocument
+}
+
+type foragingDocument struct {
+	PerceptionRadius float64 `json:"perceptionRadius"`
+	Weight           float64 `json:"weight"`
+	archetypesDocument
+}
+
+type lifecycleDocument struct {
+	Lifespan  float64 `json:"lifespan"`
+	Maturity  float64 `json:"maturity"`
+	Fertility float64 `json:"fertility"`
+	Mutation  float64 `json:"mutation"`
+	Reserve   float64 `json:"reserve"`
+	archetypesDocument
+}
+
+// perceptionDocument gives the field of view in degrees, which are easier to write by hand
+type perceptionDocument struct {
+	Range       float64 `json:"range"`
+	FieldOfView float64 `json:"fieldOfView"`
+	Occlusion   bool    `json:"occlusion"`
+	archetypesDocument
+}
+
+type bodyDocument struct {
+	Radius float64 `json:"radius"`
+	Mass   float64 `json:"mass"`
+	archetypesDocument
+}
+
+// turningDocument gives the turn rate in degrees per second
+type turningDocument struct {
+	Rate float64 `json:"rate"`
+	archetypesDocument
+}
+
+type collisionsDocument struct {
+	Restitution float64 `json:"restitution"`
+}
+
+type clusteringDocument struct {
+	Radius    float64 `json:"radius"`
+	MinPoints int     `json:"minPoints"`
+	Interval  int     `json:"interval"` // Steps
+}
+
+// settings are the parts of the document that nested objects depend on
+type settings struct {
+	maxSpeed  float64       // The default speed of every group
+	bounds    agents.Bounds // The size of the world
+	directory string        // Files named in the document are relative to this directory
+}
+
+// scenario validates the document and converts it into the options of a Scenario. Files named in
+// the document are found relative to directory.
+func (d document) scenario(directory string) (*Scenario, error) {
+	// The defaults of the agents package
+	defaultFlocking := agents.DefaultFlocking()
+	if d.TimeStep == 0 {
+		d.TimeStep = (time.Second / 60).Seconds()
+	}
+	if d.MaxSpeed == 0 {
+		d.MaxSpeed = defaultFlocking.MaxSpeed
+	}
+	if err := firstError(positive("timeStep", d.TimeStep), positive("maxSpeed", d.MaxSpeed)); err != nil {
+		return nil, err
+	}
+	scenario := &Scenario{TimeStep: time.Duration(d.TimeStep * float64(time.Second))}
+	if scenario.TimeStep <= 0 {
+		return nil, &FieldError{Path: "timeStep", Problem: fmt.Sprintf("must be at least a nanosecond, not %v", d.TimeStep)}
+	}
+
+	worldOption, bounds, err := d.world()
+	if err != nil {
+		return nil, err
+	}
+	settings := settings{maxSpeed: d.MaxSpeed, bounds: bounds, directory: directory}
+	scenario.Options = append(scenario.Options, worldOption)
+	if d.Seed != nil {
+		scenario.Options = append(scenario.Options, agents.WithSeed(*d.Seed))
+	}
+
+	// The population replaces the default population, which otherwise moves at the maxSpeed
+	scenario.Options = append(scenario.Options, agents.WithPopulation(0))
+	if d.Population == nil {
+		scenario.Options = append(scenario.Options, agents.WithAgents(agents.DefaultPopulation(), agents.Uniform{MaxSpeed: d.MaxSpeed}))
+	}
+	for i, raw := range d.Population {
+		option, err := group(raw, index("population", i), settings)
+		if err != nil {
+			return nil, err
+		}
+		scenario.Options = append(scenario.Options, option)
+	}
+
+	if d.Flocking != nil {
+		flocking := flockingDocument{
+			PerceptionRadius: defaultFlocking.PerceptionRadius,
+			MaxSpeed:         d.MaxSpeed,
+			MaxForce:         d.MaxSpeed * defaultFlocking.MaxForce / defaultFlocking.MaxSpeed,
+			Separation:       defaultFlocking.Separation,
+			Cohesion:         defaultFlocking.Cohesion,
+			Alignment:        defaultFlocking.Alignment,
+		}
+		if err := decode(d.Flocking, "flocking", &flocking); err != nil {
+			return nil, err
+		}
+		if err := firstError(
+			positive("flocking.perceptionRadius", flocking.PerceptionRadius),
+			positive("flocking.maxSpeed", flocking.MaxSpeed),
+			nonNegative("flocking.maxForce", flocking.MaxForce),
+			nonNegative("flocking.separation", flocking.Separation),
+			nonNegative("flocking.cohesion", flocking.Cohesion),
+			nonNegative("flocking.alignment", flocking.Alignment),
+		); err != nil {
+			return nil, err
+		}
+		scenario.Options = append(scenario.Options, agents.WithFlocking(agents.Flocking(flocking)))
+	}
+
+	if d.Maze != nil {
+		var maze mazeDocument
+		if err := decode(d.Maze, "maze", &maze); err != nil {
+			return nil, err
+		}
+		// Walls are a cell long and must be shorter than half the world
+		if err := firstError(atLeast("maze.columns", maze.Columns, 3), atLeast("maze.rows", maze.Rows, 3)); err != nil {
+			return nil, err
+		}
+		scenario.Options = append(scenario.Options, agents.WithMaze(maze.Columns, maze.Rows))
+	}
+
+	if d.Cells != nil {
+		cells := make([]worlds.Cell, len(d.Cells))
+		for i, raw := range d.Cells {
+			var cell cellDocument
+			path := index("cells", i)
+			if err := decode(raw, path, &cell); err != nil {
+				return nil, err
+			}
+			// The edges of a cell are walls, which must be shorter than half the world
+			if err := firstError(
+				positive(join(path, "width"), cell.Width),
+				positive(join(path, "height"), cell.Height),
+				less(join(path, "width"), cell.Width, "half the world's width", bounds.Width/2),
+				less(join(path, "height"), cell.Height, "half the world's height", bounds.Height/2),
+			); err != nil {
+				return nil, err
+			}
+			cells[i] = worlds.Cell(cell)
+		}
+		scenario.Options = append(scenario.Options, agents.WithCells(cells...))
+	}
+
+	if d.Turbulence != nil {
+		var turbulence turbulenceDocument
+		if err := decode(d.Turbulence, "turbulence", &turbulence); err != nil {
+			return nil, err
+		}
+		if err := firstError(
+			atLeast("turbulence.modes", turbulence.Modes, 1),
+			nonNegative("turbulence.strength", turbulence.Strength),
+		); err != nil {
+			return nil, err
+		}
+		scenario.Options = append(scenario.Options, agents.WithTurbulence(turbulence.Modes, turbulence.Strength))
+	}
+
+	if d.Resources != nil {
+		resources := resourcesDocument{Count: 10, Radius: 20, Capacity: 50, Regrowth: 1}
+		if err := decode(d.Resources, "resources", &resources); err != nil {
+			return nil, err
+		}
+		if err := firstError(
+			atLeast("resources.count", resources.Count, 0),
+			positive("resources.radius", resources.Radius),
+			nonNegative("resources.capacity", resources.Capacity),
+			nonNegative("resources.regrowth", resources.Regrowth),
+		); err != nil {
+			return nil, err
+		}
+		scenario.Options = append(scenario.Options, agents.WithResources(resources.Count, resources.Radius, resources.Capacity, resources.Regrowth))
+	}
+
+	if d.Collisions != nil {
+		collisions := collisionsDocument{Restitution: 1}
+		if err := decode(d.Collisions, "collisions", &collisions); err != nil {
+			return nil, err
+		}
+		if err := fraction("collisions.restitution", collisions.Restitution); err != nil {
+			return nil, err
+		}
+		scenario.Options = append(scenario.Options, agents.WithCollisions(collisions.Restitution))
+	}
+
+	if d.Clustering != nil {
+		defaults := agents.DefaultClustering()
+		clustering := clusteringDocument{Radius: defaults.Radius, MinPoints: defaults.MinPoints, Interval: defaults.Interval}
+		if err := decode(d.Clustering, "clustering", &clustering); err != nil {
+			return nil, err
+		}
+		if err := firstError(
+			positive("clustering.radius", clustering.Radius),
+			atLeast("clustering.minPoints", clustering.MinPoints, 1),
+			atLeast("clustering.interval", clustering.Interval, 1),
+		); err != nil {
+			return nil, err
+		}
+		scenario.Options = append(scenario.Options, agents.WithClustering(agents.Clustering(clustering)))
+	}
+
+	// The metabolism, way of foraging, lifecycle, perception, bodies and turning of the population
+	// may each be restricted to some of the archetypes, and given as a list to treat archetypes differently
+	for _, field := range []struct {
+		raw     json.RawMessage
+		path    string
+		convert func(raw json.RawMessage, path string) (agents.Option, error)
+	}{
+		{d.Metabolism, "metabolism", metabolism},
+		{d.Foraging, "foraging", foraging},
+		{d.Lifecycle, "lifecycle", lifecycle},
+		{d.Perception, "perception", perception},
+		{d.Bodies, "bodies", body},
+		{d.Turning, "turning", turning},
+	} {
+		options, err := oneOrMany(field.raw, field.path, field.convert)
+		if err != nil {
+			return nil, err
+		}
+		scenario.Options = append(scenario.Options, options...)
+	}
+
+	return scenario, nil
+}
+
+// world converts the world of the document into an Option and the size of the world, the default
+// is an 800 x 400 torus
+func (d document) world() (agents.Option, agents.Bounds, error) {
+	document := worldDocument{Width: 800, Height: 400}
+	if d.World != nil {
+		if err := decode(d.World, "world", &document); err != nil {
+			return nil, agents.Bounds{}, err
+		}
+	}
+	bounds := agents.Bounds{Width: document.Width, Height: document.Height}
+	if err := firstError(positive("world.width", document.Width), positive("world.height", document.Height)); err != nil {
+		return nil, bounds, err
+	}
+
+	topology := topologyDocument{X: world.Circle, Y: world.Circle}
+	if document.Topology != nil {
+		if err := decode(document.Topology, "world.topology", &topology); err != nil {
+			return nil, bounds, err
+		}
+	}
+	x, err := world.Topology1D{Kind: topology.X, Circumference: document.Width}.Space()
+	if err != nil {
+		return nil, bounds, &FieldError{Path: "world.topology.x", Problem: fmt.Sprintf("must be %q or %q, not %q", world.Line, world.Circle, topology.X)}
+	}
+	y, err := world.Topology1D{Kind: topology.Y, Circumference: document.Height}.Space()
+	if err != nil {
+		return nil, bounds, &FieldError{Path: "world.topology.y", Problem: fmt.Sprintf("must be %q or %q, not %q", world.Line, world.Circle, topology.Y)}
+	}
+	return agents.WithWorld(document.Width, document.Height, &world.MetricSpace2D{XCoord: x, YCoord: y}), bounds, nil
+}
+
+// group converts the group at path into an Option that adds it to the Scenario
+func group(raw json.RawMessage, path string, settings settings) (agents.Option, error) {
+	document := groupDocument{Archetype: "agent"}
+	if err := decode(raw, path, &document); err != nil {
+		return nil, err
+	}
+	if err := atLeast(join(path, "count"), document.Count, 0); err != nil {
+		return nil, err
+	}
+
+	spawns := make([]agents.Spawn, len(document.Spawn))
+	for i, raw := range document.Spawn {
+		spawn, err := spawn(raw, index(join(path, "spawn"), i), settings)
+		if err != nil {
+			return nil, err
+		}
+		spawns[i] = spawn
+	}
+	if len(spawns) == 0 {
+		spawns = append(spawns, agents.Uniform{MaxSpeed: settings.maxSpeed})
+	}
+
+	if document.Lover != nil && document.Archetype != "lover" {
+		return nil, &FieldError{Path: join(path, "lover"), Problem: "is only for lover groups"}
+	}
+	if document.Ruler != nil && document.Archetype != "ruler" {
+		return nil, &FieldError{Path: join(path, "ruler"), Problem: "is only for ruler groups"}
+	}
+	if document.Predator != nil && document.Archetype != "predator" {
+		return nil, &FieldError{Path: join(path, "predator"), Problem: "is only for predator groups"}
+	}
+	if document.Prey != nil && document.Archetype != "prey" {
+		return nil, &FieldError{Path: join(path, "prey"), Problem: "is only for prey groups"}
+	}
+
+	switch document.Archetype {
+	case "agent":
+		return agents.WithAgents(document.Count, spawns...), nil
+	case "lover":
+		lover, err := lover(document.Lover, join(path, "lover"), settings.maxSpeed)
+		if err != nil {
+			return nil, err
+		}
+		return agents.WithLovers(document.Count, lover, spawns...), nil
+	case "ruler":
+		ruler, err := ruler(document.Ruler, join(path, "ruler"), settings.maxSpeed)
+		if err != nil {
+			return nil, err
+		}
+		return agents.WithRulers(document.Count, ruler, spawns...), nil
+	case "predator":
+		predator, err := predator(document.Predator, join(path, "predator"), settings.maxSpeed)
+		if err != nil {
+			return nil, err
+		}
+		return agents.WithPredators(document.Count, predator, spawns...), nil
+	case "prey":
+		prey, err := prey(document.Prey, join(path, "prey"), settings.maxSpeed)
+		if err != nil {
+			return nil, err
+		}
+		return agents.WithPrey(document.Count, prey, spawns...), nil
+	default:
+		return nil, &FieldError{Path: join(path, "archetype"), Problem: "must be " + oneOfArchetypes()}
+	}
+}
+
+// lover converts the lover parameters at path into an agents.Lover
+func lover(raw json.RawMessage, path string, maxSpeed float64) (agents.Lover, error) {
+	lover := agents.DefaultLover()
+	document := loverDocument{
+		Desire:           lover.Desire,
+		Persistence:      lover.Persistence,
+		PerceptionRadius: lover.PerceptionRadius,
+		BondDistance:     lover.BondDistance,
+		BondTime:         lover.BondTime,
+		MaxSpeed:         maxSpeed,
+	}
+	if raw != nil {
+		if err := decode(raw, path, &document); err != nil {
+			return lover, err
+		}
+	}
+	bond := bondDocument(lover.Bond)
+	if document.Bond != nil {
+		if err := decode(document.Bond, join(path, "bond"), &bond); err != nil {
+			return lover, err
+		}
+	}
+	err := firstError(
+		nonNegative(join(path, "desire"), document.Desire),
+		positive(join(path, "persistence"), document.Persistence),
+		positive(join(path, "perceptionRadius"), document.PerceptionRadius),
+		positive(join(path, "bondDistance"), document.BondDistance),
+		nonNegative(join(path, "bondTime"), document.BondTime),
+		nonNegative(join(path, "bond.restLength"), bond.RestLength),
+		nonNegative(join(path, "bond.stiffness"), bond.Stiffness),
+		positive(join(path, "bond.breakingStrain"), bond.BreakingStrain),
+		positive(join(path, "maxSpeed"), document.MaxSpeed),
+	)
+
+	lover.Desire, lover.Persistence, lover.PerceptionRadius = document.Desire, document.Persistence, document.PerceptionRadius
+	lover.BondDistance, lover.BondTime, lover.Bond = document.BondDistance, document.BondTime, agents.BondSpec(bond)
+	lover.MaxSpeed = document.MaxSpeed
+	return lover, err
+}
+
+// ruler converts the ruler parameters at path into an agents.Ruler
+func ruler(raw json.RawMessage, path string, maxSpeed float64) (agents.Ruler, error) {
+	ruler := agents.DefaultRuler()
+	document := rulerDocument{
+		Establish:        ruler.Establish,
+		MinRadius:        ruler.MinRadius,
+		MaxRadius:        ruler.MaxRadius,
+		Growth:           ruler.Growth,
+		Decay:            ruler.Decay,
+		Reach:            ruler.Reach,
+		PerceptionRadius: ruler.PerceptionRadius,
+		Subjugate:        ruler.Subjugate,
+		MaxSpeed:         maxSpeed,
+	}
+	if raw != nil {
+		if err := decode(raw, path, &document); err != nil {
+			return ruler, err
+		}
+	}
+	err := firstError(
+		nonNegative(join(path, "minRadius"), document.MinRadius),
+		notLess(join(path, "establish"), document.Establish, "minRadius", document.MinRadius),
+		notLess(join(path, "maxRadius"), document.MaxRadius, "establish", document.Establish),
+		nonNegative(join(path, "growth"), document.Growth),
+		nonNegative(join(path, "decay"), document.Decay),
+		nonNegative(join(path, "reach"), document.Reach),
+		nonNegative(join(path, "perceptionRadius"), document.PerceptionRadius),
+		positive(join(path, "maxSpeed"), document.MaxSpeed),
+	)
+
+	ruler.Establish, ruler.MinRadius, ruler.MaxRadius = document.Establish, document.MinRadius, document.MaxRadius
+	ruler.Growth, ruler.Decay, ruler.Reach = document.Growth, document.Decay, document.Reach
+	ruler.PerceptionRadius, ruler.Subjugate, ruler.MaxSpeed = document.PerceptionRadius, document.Subjugate, document.MaxSpeed
+	return ruler, err
+}
+
+// predator converts the predator parameters at path into an agents.Predator, its speed defaults to
+// a little faster than maxSpeed
+func predator(raw json.RawMessage, path string, maxSpeed float64) (agents.Predator, error) {
+	predator := agents.DefaultPredator()
+	document := predatorDocument{
+		PerceptionRadius: predator.PerceptionRadius,
+		Reach:            predator.Reach,
+		Nourishment:      predator.Nourishment,
+		Lead:             predator.Lead,
+		MaxSpeed:         maxSpeed * agents.DefaultPredator().MaxSpeed / agents.DefaultPrey().MaxSpeed,
+	}
+	if raw != nil {
+		if err := decode(raw, path, &document); err != nil {
+			return predator, err
+		}
+	}
+	err := firstError(
+		positive(join(path, "perceptionRadius"), document.PerceptionRadius),
+		positive(join(path, "reach"), document.Reach),
+		nonNegative(join(path, "nourishment"), document.Nourishment),
+		nonNegative(join(path, "lead"), document.Lead),
+		positive(join(path, "maxSpeed"), document.MaxSpeed),
+	)
+
+	predator.PerceptionRadius, predator.Reach, predator.Nourishment = document.PerceptionRadius, document.Reach, document.Nourishment
+	predator.Lead, predator.MaxSpeed = document.Lead, document.MaxSpeed
+	return predator, err
+}
+
+// prey converts the prey parameters at path into an agents.Prey
+func prey(raw json.RawMessage, path string, maxSpeed float64) (agents.Prey, error) {
+	prey := agents.DefaultPrey()
+	document := preyDocument{PerceptionRadius: prey.PerceptionRadius, Fear: prey.Fear, MaxSpeed: maxSpeed}
+	if raw != nil {
+		if err := decode(raw, path, &document); err != nil {
+			return prey, err
+		}
+	}
+	err := firstError(
+		positive(join(path, "perceptionRadius"), document.PerceptionRadius),
+		nonNegative(join(path, "fear"), document.Fear),
+		positive(join(path, "maxSpeed"), document.MaxSpeed),
+	)
+
+	prey.PerceptionRadius, prey.Fear, prey.MaxSpeed = document.PerceptionRadius, document.Fear, document.MaxSpeed
+	return prey, err
+}
+
+// oneOrMany converts the object at path, or each object of the list at path, into an Option
+func oneOrMany(
+	raw json.RawMessage,
+	path string,
+	convert func(raw json.RawMessage, path string) (agents.Option, error),
+) ([]agents.Option, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) == 0 || trimmed[0] != '[' {
+		option, err := convert(raw, path)
+		if err != nil {
+			return nil, err
+		}
+		return []agents.Option{option}, nil
+	}
+
+	var list []json.RawMessage
+	if err := decode(raw, path, &list); err != nil {
+		return nil, err
+	}
+	options := make([]agents.Option, len(list))
+	for i, element := range list {
+		option, err := convert(element, index(path, i))
+		if err != nil {
+			return nil, err
+		}
+		options[i] = option
+	}
+	return options, nil
+}
+
+// metabolism converts the metabolism at path into an Option, its fields default to
+// agents.DefaultMetabolism
+func metabolism(raw json.RawMessage, path string) (agents.Option, error) {
+	defaults := agents.DefaultMetabolism()
+	document := metabolismDocument{
+		Energy:   defaults.Energy,
+		Capacity: defaults.Capacity,
+		Basal:    defaults.Basal,
+		Movement: defaults.Movement,
+		Appetite: defaults.Appetite,
+	}
+	if err := decode(raw, path, &document); err != nil {
+		return nil, err
+	}
+	if err := firstError(
+		nonNegative(join(path, "energy"), document.Energy),
+		positive(join(path, "capacity"), document.Capacity),
+		notLess(join(path, "capacity"), document.Capacity, "energy", document.Energy),
+		nonNegative(join(path, "basal"), document.Basal),
+		nonNegative(join(path, "movement"), document.Movement),
+		nonNegative(join(path, "appetite"), document.Appetite),
+		archetypes(join(path, "archetypes"), document.Archetypes),
+	); err != nil {
+		return nil, err
+	}
+	return agents.WithMetabolism(agents.Metabolism{
+		Energy:    document.Energy,
+		Capacity:  document.Capacity,
+		Basal:     document.Basal,
+		Movement:  document.Movement,
+		Appetite:  document.Appetite,
+		Hibernate: document.Hibernate,
+	}, document.Archetypes...), nil
+}
+
+// foraging converts the foraging at path into an Option
+func foraging(raw json.RawMessage, path string) (agents.Option, error) {
+	document := foragingDocument{PerceptionRadius: 100, Weight: 1}
+	if err := decode(raw, path, &document); err != nil {
+		return nil, err
+	}
+	if err := firstError(
+		positive(join(path, "perceptionRadius"), document.PerceptionRadius),
+		nonNegative(join(path, "weight"), document.Weight),
+		archetypes(join(path, "archetypes"), document.Archetypes),
+	); err != nil {
+		return nil, err
+	}
+	return agents.WithForaging(document.PerceptionRadius, document.Weight, document.Archetypes...), nil
+}
+
+// lifecycle converts the lifecycle at path into an Option
+func lifecycle(raw json.RawMessage, path string) (agents.Option, error) {
+	var document lifecycleDocument
+	if err := decode(raw, path, &document); err != nil {
+		return nil, err
+	}
+	if err := firstError(
+		nonNegative(join(path, "lifespan"), document.Lifespan),
+		nonNegative(join(path, "maturity"), document.Maturity),
+		nonNegative(join(path, "fertility"), document.Fertility),
+		nonNegative(join(path, "mutation"), document.Mutation),
+		nonNegative(join(path, "reserve"), document.Reserve),
+		archetypes(join(path, "archetypes"), document.Archetypes),
+	); err != nil {
+		return nil, err
+	}
+	return agents.WithLifecycle(agents.Lifecycle{
+		Lifespan:  document.Lifespan,
+		Maturity:  document.Maturity,
+		Fertility: document.Fertility,
+		Mutation:  document.Mutation,
+		Reserve:   document.Reserve,
+	}, document.Archetypes...), nil
+}
+
+// perception converts the perception at path into an Option, its fields default to
+// agents.DefaultPerception
+func perception(raw json.RawMessage, path string) (agents.Option, error) {
+	defaults := agents.DefaultPerception()
+	degrees := 180 / math.Pi
+	document := perceptionDocument{
+		Range:       defaults.Range,
+		FieldOfView: defaults.FieldOfView * degrees,
+		Occlusion:   defaults.Occlusion,
+	}
+	if err := decode(raw, path, &document); err != nil {
+		return nil, err
+	}
+	if err := firstError(
+		nonNegative(join(path, "range"), document.Range),
+		nonNegative(join(path, "fieldOfView"), document.FieldOfView),
+		archetypes(join(path, "archetypes"), document.Archetypes),
+	); err != nil {
+		return nil, err
+	}
+	return agents.WithPerception(agents.Perception{
+		Range:       document.Range,
+		FieldOfView: document.FieldOfView / degrees,
+		Occlusion:   document.Occlusion,
+	}, document.Archetypes...), nil
+}
+
+// body converts the body at path into an Option, its fields default to agents.DefaultBody
+func body(raw json.RawMessage, path string) (agents.Option, error) {
+	defaults := agents.DefaultBody()
+	document := bodyDocument{Radius: defaults.Radius, Mass: defaults.Mass}
+	if err := decode(raw, path, &document); err != nil {
+		return nil, err
+	}
+	if err := firstError(
+		positive(join(path, "radius"), document.Radius),
+		nonNegative(join(path, "mass"), document.Mass),
+		archetypes(join(path, "archetypes"), document.Archetypes),
+	); err != nil {
+		return nil, err
+	}
+	return agents.WithBodies(agents.Body{Radius: document.Radius, Mass: document.Mass}, document.Archetypes...), nil
+}
+
+// turning converts the turning at path into an Option
+func turning(raw json.RawMessage, path string) (agents.Option, error) {
+	document := turningDocument{Rate: 180}
+	if err := decode(raw, path, &document); err != nil {
+		return nil, err
+	}
+	if err := firstError(
+		nonNegative(join(path, "rate"), document.Rate),
+		archetypes(join(path, "archetypes"), document.Archetypes),
+	); err != nil {
+		return nil, err
+	}
+	return agents.WithTurnRate(document.Rate*math.Pi/180, document.Archetypes...), nil
+}
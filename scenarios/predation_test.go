@@ -0,0 +1,48 @@
+//go:build long
+
+package scenarios
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// TestLoad_Predation_Oscillates checks that predators and prey rise and fall in turn: prey multiply
+// until predators thrive on them, the prey decline and the predators then starve. It simulates five
+// minutes of predation, so only runs with go test -tags long.
+func TestLoad_Predation_Oscillates(t *testing.T) {
+	scenario, err := Load("predation.json")
+	assert.NoError(t, err)
+	simulation := scenario.Initialise()
+
+	// The time and size of the largest population of each archetype so far, and the smallest since
+	type extremes struct {
+		peakTime        float64
+		peak, sincePeak int
+	}
+	populations := map[string]*extremes{}
+	for _, archetype := range []string{"prey", "predator"} {
+		count := simulation.Stats().Archetypes[archetype]
+		populations[archetype] = &extremes{peak: count, sincePeak: count}
+	}
+	for simulation.Stats().Time.Value < 300 {
+		simulation.GetFrameAt(simulation.Stats().Time.Value + 5)
+		stats := simulation.Stats()
+		for archetype, population := range populations {
+			count := stats.Archetypes[archetype]
+			if count > population.peak {
+				population.peakTime, population.peak, population.sincePeak = stats.Time.Value, count, count
+			}
+			if count < population.sincePeak {
+				population.sincePeak = count
+			}
+		}
+	}
+
+	prey, predators := populations["prey"], populations["predator"]
+	assert.Greater(t, prey.peak, 80*3/2, "prey multiply")
+	assert.Less(t, prey.sincePeak, prey.peak*2/3, "prey decline")
+	assert.Greater(t, predators.peak, 4*4, "predators multiply")
+	assert.Less(t, predators.sincePeak, predators.peak/2, "predators starve")
+	assert.Less(t, prey.peakTime, predators.peakTime, "predators peak after prey")
+}
@@ -0,0 +1,200 @@
+// Package scenarios reads Scenarios from JSON files. A scenario file describes the world, the
+// timestep and the population mix, for example
+//
+//	{
+//	  "world": {"width": 800, "height": 400, "topology": {"x": "circle", "y": "circle"}},
+//	  "timeStep": 0.0166667,
+//	  "maxSpeed": 10,
+//	  "population": [
+//	    {"archetype": "agent", "count": 100, "spawn": [{"kind": "uniform"}]},
+//	    {"archetype": "lover", "count": 10, "lover": {"desire": 3}},
+//	    {"archetype": "ruler", "count": 3}
+//	  ],
+//	  "flocking": {"perceptionRadius": 50},
+//	  "maze": {"columns": 8, "rows": 4},
+//	  "cells": [{"x": 100, "y": 100, "width": 50, "height": 20}],
+//	  "turbulence": {"modes": 6, "strength": 5},
+//	  "resources": {"count": 10, "radius": 20, "capacity": 50, "regrowth": 1},
+//	  "metabolism": {"energy": 100, "capacity": 100, "basal": 0.5, "movement": 0.05, "appetite": 20},
+//	  "foraging": {"perceptionRadius": 100, "weight": 1},
+//	  "lifecycle": {"lifespan": 60, "maturity": 5, "fertility": 0.05, "mutation": 0.1},
+//	  "perception": {"range": 80, "fieldOfView": 270, "occlusion": true},
+//	  "bodies": {"radius": 4, "mass": 1},
+//	  "turning": {"rate": 180},
+//	  "collisions": {"restitution": 0.5},
+//	  "clustering": {"radius": 25, "minPoints": 4, "interval": 10}
+//	}
+//
+// Every field is optional and defaults to the value the agents package uses. Omitting the
+// population gives the default population of plain agents, at speeds up to the maxSpeed. A group's archetype is one of agent,
+// lover, ruler, predator or prey, and the parameters of the archetype are given in the field of the
+// same name, eg. {"archetype": "predator", "count": 5, "predator": {"reach": 8}}.
+//
+// The metabolism, foraging, lifecycle, perception, bodies and turning apply to every agent of the
+// population, unless they list the archetypes they apply to, eg. "metabolism": {"archetypes":
+// ["predator"]}. Each may also be a list, to give different archetypes different parameters. Agents
+// without a metabolism need no energy, those without a lifecycle neither die of old age nor
+// reproduce, those without a perception sense everything, those without a body collide with nothing
+// and those without a turn rate turn as sharply as they are steered. A perception's field of view is
+// in degrees, and a turn rate in degrees per second. Bodies only collide if the scenario has collisions, whose restitution is 1 for
+// elastic collisions down to 0 for perfectly inelastic ones. A body of zero mass is immovable.
+//
+// Each group is placed by its spawn strategies in turn, uniformly if it has none. The kinds of
+// strategy and their fields are
+//
+//	uniform   maxSpeed                  positions and velocities
+//	clusters  count, spread             positions in Gaussian clusters
+//	ring      x, y, radius, width       positions around a ring
+//	grid      jitter                    positions on a lattice
+//	density   image                     positions sampled from the brightness of an image file
+//	heading   angle, speed, jitter      velocities in one direction, angles in degrees
+//
+// so that eg. [{"kind": "clusters"}, {"kind": "heading", "angle": 90}] spawns flocks that set off
+// in the same direction.
+package scenarios
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+	"tjweldon/archetypal-agents/domain/agents"
+)
+
+// Scenario is a validated scenario file, from which any number of identical Scenarios can be
+// initialised
+type Scenario struct {
+	TimeStep time.Duration
+	Options  []agents.Option
+}
+
+// Initialise initialises the agents.Scenario the file describes, the options are applied after
+// those from the file
+func (s *Scenario) Initialise(options ...agents.Option) *agents.Scenario {
+	return agents.InitialiseScenario(s.TimeStep, append(append([]agents.Option{}, s.Options...), options...)...)
+}
+
+// Load reads the scenario file at path. Problems with the content of the file are reported as a
+// *FieldError.
+func Load(path string) (*Scenario, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scenario, err := parse(file, filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return scenario, nil
+}
+
+// Parse reads a scenario file from r (see Load). Files named in it are found relative to the
+// working directory.
+func Parse(r io.Reader) (*Scenario, error) {
+	return parse(r, ".")
+}
+
+// parse reads a scenario file from r, files named in it are found relative to directory
+func parse(r io.Reader, directory string) (*Scenario, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var document document
+	if err := decode(data, "", &document); err != nil {
+		return nil, err
+	}
+	return document.scenario(directory)
+}
+
+// FieldError is a problem with a field of a scenario file. Path locates the field within the
+// file, eg. population[1].count, and is empty for the file as a whole.
+type FieldError struct {
+	Path    string
+	Problem string
+}
+
+// Error implements error
+func (e *FieldError) Error() string {
+	if e.Path == "" {
+		return e.Problem
+	}
+	return e.Path + ": " + e.Problem
+}
+
+// decode decodes the JSON in data into value, which is found at path within the file. Values are
+// only overwritten by fields that are present, so defaults can be set beforehand. Unknown fields
+// are an error so that misspelt fields are not silently ignored.
+func decode(data []byte, path string, value any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(value)
+
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+	switch {
+	case err == nil:
+		return nil
+	case err == io.EOF:
+		return &FieldError{Path: path, Problem: "is empty"}
+	case err == io.ErrUnexpectedEOF:
+		return &FieldError{Path: path, Problem: "ends unexpectedly"}
+	case errors.As(err, &syntaxError):
+		line := 1 + bytes.Count(data[:syntaxError.Offset], []byte("\n"))
+		return &FieldError{Path: path, Problem: fmt.Sprintf("%s on line %d", syntaxError, line)}
+	case errors.As(err, &typeError):
+		return &FieldError{
+			Path:    join(path, typeError.Field),
+			Problem: fmt.Sprintf("must be %s, not %s", describe(typeError.Type), typeError.Value),
+		}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return &FieldError{Path: join(path, field), Problem: "is not a known field"}
+	default:
+		return &FieldError{Path: path, Problem: err.Error()}
+	}
+}
+
+// describe names the type of a field in the terms of the file
+func describe(field reflect.Type) string {
+	switch field.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "a whole number"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Slice:
+		return "a list"
+	case reflect.Pointer:
+		return describe(field.Elem())
+	default:
+		return "an object"
+	}
+}
+
+// join appends a field to a path
+func join(path, field string) string {
+	if path == "" {
+		return field
+	}
+	if field == "" {
+		return path
+	}
+	return path + "." + field
+}
+
+// index appends an index to a path
+func index(path string, index int) string {
+	return fmt.Sprintf("%s[%d]", path, index)
+}
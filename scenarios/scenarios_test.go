@@ -0,0 +1,247 @@
+package scenarios
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"tjweldon/archetypal-agents/domain/agents"
+	"tjweldon/archetypal-agents/worlds"
+)
+
+func TestLoad_Headline(t *testing.T) {
+	scenario, err := Load("headline.json")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(16666700), scenario.TimeStep)
+
+	simulation := scenario.Initialise(agents.WithSeed(1))
+	frame := simulation.GetNextFrame()
+	assert.Len(t, frame, 113)
+	assert.NotEmpty(t, simulation.Walls())
+	assert.NotEmpty(t, simulation.FlowSamples(4, 4))
+}
+
+func TestParse_Defaults(t *testing.T) {
+	scenario, err := Parse(strings.NewReader(`{}`))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second/60, scenario.TimeStep)
+	assert.Len(t, scenario.Initialise().GetNextFrame(), 100)
+}
+
+func TestParse_MaxSpeedAppliesToTheDefaultPopulation(t *testing.T) {
+	scenario, err := Parse(strings.NewReader(`{"maxSpeed": 2}`))
+	assert.NoError(t, err)
+
+	state := scenario.Initialise().State()
+	assert.Equal(t, agents.DefaultPopulation(), state.Population())
+	for _, agent := range state.Agents {
+		assert.LessOrEqual(t, agent.Velocity.Mag(), 2.0)
+	}
+}
+
+func TestParse_Seed(t *testing.T) {
+	scenario, err := Parse(strings.NewReader(`{"seed": 7, "population": [{"count": 5}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), scenario.Initialise().Seed)
+	assert.Equal(t, scenario.Initialise().GetNextFrame(), scenario.Initialise().GetNextFrame())
+}
+
+func TestParse_LocatesProblems(t *testing.T) {
+	cases := map[string]string{
+		`{"timeStep": -1}`:                                                             "timeStep",
+		`{"timeStep": 1e-10}`:                                                          "timeStep",
+		`{"timeStep": "fast"}`:                                                         "timeStep",
+		`{"world": {"width": 0}}`:                                                      "world.width",
+		`{"world": {"topology": {"x": "sphere"}}}`:                                     "world.topology.x",
+		`{"world": {"depth": 3}}`:                                                      "world.depth",
+		`{"population": [{"count": 1}, {"count": -1}]}`:                                "population[1].count",
+		`{"population": [{"archetype": "dragon"}]}`:                                    "population[0].archetype",
+		`{"population": [{"spawn": [{"kind": "everywhere"}]}]}`:                        "population[0].spawn[0].kind",
+		`{"population": [{"archetype": "lover", "lover": {"desire": -1}}]}`:            "population[0].lover.desire",
+		`{"population": [{"archetype": "lover", "lover": {"bond": {"stiffnes": 1}}}]}`: "population[0].lover.bond.stiffnes",
+		`{"population": [{"archetype": "agent", "ruler": {}}]}`:                        "population[0].ruler",
+		`{"population": [{"archetype": "ruler", "ruler": {"establish": 200}}]}`:        "population[0].ruler.maxRadius",
+		`{"flocking": {"cohesion": -1}}`:                                               "flocking.cohesion",
+		`{"maze": {"columns": 2, "rows": 4}}`:                                          "maze.columns",
+		`{"cells": [{"width": 10, "height": 10}, {"width": 10}]}`:                      "cells[1].height",
+		`{"cells": [{"width": 400, "height": 10}]}`:                                    "cells[0].width",
+		`{"turbulence": {"modes": 0}}`:                                                 "turbulence.modes",
+		`{"resources": {"radius": 0}}`:                                                 "resources.radius",
+		`{"metabolism": {"energy": 200}}`:                                              "metabolism.capacity",
+		`{"foraging": {"weight": -1}}`:                                                 "foraging.weight",
+		`{"lifecycle": {"archetypes": ["prey", "wolf"]}}`:                              "lifecycle.archetypes[1]",
+		`{"metabolism": [{}, {"basal": -1}]}`:                                          "metabolism[1].basal",
+		`{"perception": {"fieldOfView": -90}}`:                                         "perception.fieldOfView",
+		`{"bodies": [{}, {"radius": 0}]}`:                                              "bodies[1].radius",
+		`{"collisions": {"restitution": 1.5}}`:                                         "collisions.restitution",
+		`{"turning": {"rate": -90}}`:                                                   "turning.rate",
+		`{"clustering": {"interval": 0}}`:                                              "clustering.interval",
+		`{"population": [{"archetype": "prey", "predator": {}}]}`:                      "population[0].predator",
+		`{"population": [{"archetype": "predator", "predator": {"reach": 0}}]}`:        "population[0].predator.reach",
+		`{"world": `: "",
+	}
+	for document, path := range cases {
+		_, err := Parse(strings.NewReader(document))
+		var fieldError *FieldError
+		if assert.True(t, errors.As(err, &fieldError), document) {
+			assert.Equal(t, path, fieldError.Path, document)
+		}
+	}
+}
+
+func TestParse_Ecosystem(t *testing.T) {
+	scenario, err := Parse(strings.NewReader(`{
+		"population": [{"count": 5}],
+		"resources": {"count": 4},
+		"metabolism": {"energy": 30, "hibernate": true},
+		"foraging": {}
+	}`))
+	assert.NoError(t, err)
+
+	simulation := scenario.Initialise(agents.WithSeed(1))
+	assert.Len(t, simulation.Patches(), 4)
+	for _, coords := range simulation.GetNextFrame() {
+		assert.NotNil(t, coords.Energy)
+		assert.Less(t, coords.Energy.Value, 30.0)
+	}
+}
+
+func TestParse_Perception(t *testing.T) {
+	scenario, err := Parse(strings.NewReader(`{
+		"population": [{"count": 2}, {"archetype": "prey", "count": 2}],
+		"perception": [{"fieldOfView": 90, "archetypes": ["agent"]}, {"range": 30, "occlusion": false, "archetypes": ["prey"]}]
+	}`))
+	assert.NoError(t, err)
+
+	snapshot, err := scenario.Initialise().Snapshot()
+	assert.NoError(t, err)
+	perceptions := make([]agents.Perception, len(snapshot.Agents))
+	for index, record := range snapshot.Agents {
+		perceptions[index] = *record.Perception
+	}
+	sight := agents.DefaultPerception()
+	sight.FieldOfView = math.Pi / 2
+	smell := agents.Perception{Range: 30, FieldOfView: agents.DefaultPerception().FieldOfView}
+	assert.InDeltaSlice(t, []float64{sight.FieldOfView, sight.FieldOfView, smell.FieldOfView, smell.FieldOfView},
+		[]float64{perceptions[0].FieldOfView, perceptions[1].FieldOfView, perceptions[2].FieldOfView, perceptions[3].FieldOfView}, 1e-9)
+	assert.Equal(t, []float64{sight.Range, sight.Range, smell.Range, smell.Range},
+		[]float64{perceptions[0].Range, perceptions[1].Range, perceptions[2].Range, perceptions[3].Range})
+	assert.Equal(t, []bool{true, true, false, false},
+		[]bool{perceptions[0].Occlusion, perceptions[1].Occlusion, perceptions[2].Occlusion, perceptions[3].Occlusion})
+}
+
+func TestParse_Cells(t *testing.T) {
+	scenario, err := Parse(strings.NewReader(`{"cells": [{"x": 10, "y": 20, "width": 30, "height": 40}]}`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []worlds.Cell{{X: 10, Y: 20, Width: 30, Height: 40}}, scenario.Initialise().Cells())
+}
+
+func TestParse_Collisions(t *testing.T) {
+	scenario, err := Parse(strings.NewReader(`{
+		"population": [{"count": 2}, {"archetype": "prey", "count": 1}],
+		"bodies": {"radius": 6, "archetypes": ["agent"]},
+		"collisions": {"restitution": 0.25}
+	}`))
+	assert.NoError(t, err)
+
+	snapshot, err := scenario.Initialise().Snapshot()
+	assert.NoError(t, err)
+	assert.Equal(t, &agents.Collisions{Restitution: 0.25}, snapshot.Collisions)
+	assert.Equal(t, &agents.Body{Radius: 6, Mass: 1}, snapshot.Agents[0].Body)
+	assert.Equal(t, &agents.Body{Radius: 6, Mass: 1}, snapshot.Agents[1].Body)
+	assert.Nil(t, snapshot.Agents[2].Body)
+}
+
+func TestParse_Turning(t *testing.T) {
+	scenario, err := Parse(strings.NewReader(`{
+		"population": [{"count": 1}, {"archetype": "prey", "count": 1}],
+		"turning": [{"archetypes": ["agent"]}, {"rate": 90, "archetypes": ["prey"]}]
+	}`))
+	assert.NoError(t, err)
+
+	snapshot, err := scenario.Initialise().Snapshot()
+	assert.NoError(t, err)
+	assert.InDelta(t, math.Pi, snapshot.Agents[0].TurnRate, 1e-9)
+	assert.InDelta(t, math.Pi/2, snapshot.Agents[1].TurnRate, 1e-9)
+}
+
+func TestParse_Clustering(t *testing.T) {
+	scenario, err := Parse(strings.NewReader(`{"clustering": {"radius": 30, "interval": 5}}`))
+	assert.NoError(t, err)
+
+	snapshot, err := scenario.Initialise().Snapshot()
+	assert.NoError(t, err)
+	assert.Equal(t, &agents.Clustering{Radius: 30, MinPoints: 4, Interval: 5}, snapshot.Clustering)
+}
+
+func TestLoad_Predation(t *testing.T) {
+	scenario, err := Load("predation.json")
+	assert.NoError(t, err)
+
+	simulation := scenario.Initialise()
+	stats := simulation.Stats()
+	assert.Equal(t, map[string]int{"predator": 4, "prey": 80}, stats.Archetypes)
+	for _, coords := range simulation.GetNextFrame() {
+		assert.NotNil(t, coords.Energy, "predators and prey have their own metabolisms")
+	}
+}
+
+func TestLoad_Spawns(t *testing.T) {
+	directory := t.TempDir()
+	picture := image.NewGray(image.Rect(0, 0, 2, 1))
+	picture.SetGray(1, 0, color.Gray{Y: 255})
+	file, err := os.Create(filepath.Join(directory, "right.png"))
+	assert.NoError(t, err)
+	assert.NoError(t, png.Encode(file, picture))
+	assert.NoError(t, file.Close())
+
+	document := `{"population": [
+		{"count": 10, "spawn": [{"kind": "clusters", "count": 2}, {"kind": "heading", "angle": 90, "speed": 2}]},
+		{"count": 10, "spawn": [{"kind": "ring", "radius": 50}]},
+		{"count": 10, "spawn": [{"kind": "grid", "jitter": 1}]},
+		{"count": 10, "spawn": [{"kind": "density", "image": "right.png"}]}
+	]}`
+	path := filepath.Join(directory, "spawns.json")
+	assert.NoError(t, os.WriteFile(path, []byte(document), 0o644))
+
+	scenario, err := Load(path)
+	assert.NoError(t, err)
+	frame := scenario.Initialise().GetFrameAt(0)
+	assert.Len(t, frame, 40)
+	for _, coords := range frame[30:] {
+		assert.GreaterOrEqual(t, coords.X.Value, 400.0)
+	}
+}
+
+func TestParse_LocatesSpawnProblems(t *testing.T) {
+	cases := map[string]string{
+		`{"population": [{"spawn": [3]}]}`:                                       "population[0].spawn[0]",
+		`{"population": [{"spawn": [{"kind": "grid", "radius": 3}]}]}`:           "population[0].spawn[0].radius",
+		`{"population": [{"spawn": [{"kind": "clusters", "count": 0}]}]}`:        "population[0].spawn[0].count",
+		`{"population": [{"spawn": [{"kind": "density"}]}]}`:                     "population[0].spawn[0].image",
+		`{"population": [{"spawn": [{"kind": "density", "image": "nowhere"}]}]}`: "population[0].spawn[0].image",
+		`{"population": [{"spawn": [{"kind": "heading", "speed": -1}]}]}`:        "population[0].spawn[0].speed",
+	}
+	for document, path := range cases {
+		_, err := Parse(strings.NewReader(document))
+		var fieldError *FieldError
+		if assert.True(t, errors.As(err, &fieldError), document) {
+			assert.Equal(t, path, fieldError.Path, document)
+		}
+	}
+}
+
+func TestLoad_Examples(t *testing.T) {
+	for _, path := range []string{"headline.json", "flocks.json", "predation.json"} {
+		_, err := Load(path)
+		assert.NoError(t, err, path)
+	}
+}
@@ -0,0 +1,150 @@
+package scenarios
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/gif"  // Density images may be GIFs
+	_ "image/jpeg" // or JPEGs
+	_ "image/png"  // or PNGs
+	"math"
+	"os"
+	"path/filepath"
+	"tjweldon/archetypal-agents/domain/agents"
+)
+
+// kindDocument names the kind of a spawn strategy, it is part of the document of every kind
+type kindDocument struct {
+	Kind string `json:"kind"`
+}
+
+type uniformDocument struct {
+	kindDocument
+	MaxSpeed float64 `json:"maxSpeed"`
+}
+
+type clustersDocument struct {
+	kindDocument
+	Count  int     `json:"count"`
+	Spread float64 `json:"spread"`
+}
+
+type ringDocument struct {
+	kindDocument
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Radius float64 `json:"radius"`
+	Width  float64 `json:"width"`
+}
+
+type gridDocument struct {
+	kindDocument
+	Jitter float64 `json:"jitter"`
+}
+
+// densityDocument names an image file, relative to the scenario file
+type densityDocument struct {
+	kindDocument
+	Image string `json:"image"`
+}
+
+// headingDocument gives angles in degrees, which are easier to write by hand
+type headingDocument struct {
+	kindDocument
+	Angle  float64 `json:"angle"`
+	Speed  float64 `json:"speed"`
+	Jitter float64 `json:"jitter"`
+}
+
+// spawn converts the spawn strategy at path into an agents.Spawn. Each kind of strategy has its own
+// fields, which default to values that suit the world.
+func spawn(raw json.RawMessage, path string, settings settings) (agents.Spawn, error) {
+	var kind kindDocument
+	if err := json.Unmarshal(raw, &kind); err != nil {
+		return nil, &FieldError{Path: path, Problem: "must be an object with a kind"}
+	}
+	width, height := settings.bounds.Width, settings.bounds.Height
+
+	switch kind.Kind {
+	case "uniform":
+		document := uniformDocument{MaxSpeed: settings.maxSpeed}
+		if err := decode(raw, path, &document); err != nil {
+			return nil, err
+		}
+		return agents.Uniform{MaxSpeed: document.MaxSpeed}, nonNegative(join(path, "maxSpeed"), document.MaxSpeed)
+
+	case "clusters":
+		document := clustersDocument{Count: 3, Spread: math.Min(width, height) / 20}
+		if err := decode(raw, path, &document); err != nil {
+			return nil, err
+		}
+		return agents.Clusters{Count: document.Count, Spread: document.Spread}, firstError(
+			atLeast(join(path, "count"), document.Count, 1),
+			nonNegative(join(path, "spread"), document.Spread),
+		)
+
+	case "ring":
+		document := ringDocument{X: width / 2, Y: height / 2, Radius: math.Min(width, height) / 4}
+		if err := decode(raw, path, &document); err != nil {
+			return nil, err
+		}
+		return agents.Ring{X: document.X, Y: document.Y, Radius: document.Radius, Width: document.Width}, firstError(
+			nonNegative(join(path, "radius"), document.Radius),
+			nonNegative(join(path, "width"), document.Width),
+		)
+
+	case "grid":
+		var document gridDocument
+		if err := decode(raw, path, &document); err != nil {
+			return nil, err
+		}
+		return agents.Grid{Jitter: document.Jitter}, nonNegative(join(path, "jitter"), document.Jitter)
+
+	case "density":
+		var document densityDocument
+		if err := decode(raw, path, &document); err != nil {
+			return nil, err
+		}
+		if document.Image == "" {
+			return nil, &FieldError{Path: join(path, "image"), Problem: "must name an image file"}
+		}
+		return density(filepath.Join(settings.directory, document.Image), join(path, "image"))
+
+	case "heading":
+		document := headingDocument{Speed: settings.maxSpeed}
+		if err := decode(raw, path, &document); err != nil {
+			return nil, err
+		}
+		radians := math.Pi / 180
+		return agents.Heading{
+			Angle:  document.Angle * radians,
+			Speed:  document.Speed,
+			Jitter: document.Jitter * radians,
+		}, firstError(nonNegative(join(path, "speed"), document.Speed), nonNegative(join(path, "jitter"), document.Jitter))
+
+	default:
+		return nil, &FieldError{
+			Path:    join(path, "kind"),
+			Problem: fmt.Sprintf(`must be "uniform", "clusters", "ring", "grid", "density" or "heading", not %q`, kind.Kind),
+		}
+	}
+}
+
+// density reads the image file named at path into an agents.Density
+func density(file, path string) (agents.Spawn, error) {
+	reader, err := os.Open(file)
+	if err != nil {
+		return nil, &FieldError{Path: path, Problem: err.Error()}
+	}
+	defer reader.Close()
+
+	picture, _, err := image.Decode(reader)
+	if err != nil {
+		return nil, &FieldError{Path: path, Problem: fmt.Sprintf("%s is not an image: %v", file, err)}
+	}
+	density, err := agents.NewDensity(picture)
+	if err != nil {
+		return nil, &FieldError{Path: path, Problem: err.Error()}
+	}
+	return density, nil
+}
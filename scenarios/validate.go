@@ -0,0 +1,98 @@
+package scenarios
+
+import (
+	"fmt"
+	"strings"
+)
+
+// firstError returns the first of errors that is not nil, checks are written in the order the
+// fields appear in the file so that the first problem is reported
+func firstError(errors ...error) error {
+	for _, err := range errors {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// positive checks that the field at path is greater than zero
+func positive(path string, value float64) error {
+	if value <= 0 {
+		return &FieldError{Path: path, Problem: fmt.Sprintf("must be positive, not %v", value)}
+	}
+	return nil
+}
+
+// nonNegative checks that the field at path is not less than zero
+func nonNegative(path string, value float64) error {
+	if value < 0 {
+		return &FieldError{Path: path, Problem: fmt.Sprintf("must not be negative, not %v", value)}
+	}
+	return nil
+}
+
+// atLeast checks that the whole number field at path is at least minimum
+func atLeast(path string, value, minimum int) error {
+	if value < minimum {
+		return &FieldError{Path: path, Problem: fmt.Sprintf("must be at least %d, not %d", minimum, value)}
+	}
+	return nil
+}
+
+// notLess checks that the field at path is not less than the sibling field called other
+func notLess(path string, value float64, other string, minimum float64) error {
+	if value < minimum {
+		return &FieldError{Path: path, Problem: fmt.Sprintf("must not be less than %s (%v), not %v", other, minimum, value)}
+	}
+	return nil
+}
+
+// less checks that the field at path is less than the quantity described by other
+func less(path string, value float64, other string, maximum float64) error {
+	if value >= maximum {
+		return &FieldError{Path: path, Problem: fmt.Sprintf("must be less than %s (%v), not %v", other, maximum, value)}
+	}
+	return nil
+}
+
+// fraction checks that the field at path is between zero and one inclusive
+func fraction(path string, value float64) error {
+	if value < 0 || value > 1 {
+		return &FieldError{Path: path, Problem: fmt.Sprintf("must be between 0 and 1, not %v", value)}
+	}
+	return nil
+}
+
+// archetypeNames are the archetypes a group may have, as they are written in the file
+var archetypeNames = []string{"agent", "lover", "ruler", "predator", "prey"}
+
+// isArchetype reports whether name is one of archetypeNames
+func isArchetype(name string) bool {
+	for _, archetype := range archetypeNames {
+		if name == archetype {
+			return true
+		}
+	}
+	return false
+}
+
+// oneOfArchetypes describes the archetypeNames as a choice, eg. "agent", "lover" or "ruler"
+func oneOfArchetypes() string {
+	quoted := make([]string, len(archetypeNames))
+	for i, name := range archetypeNames {
+		quoted[i] = fmt.Sprintf("%q", name)
+	}
+	last := len(quoted) - 1
+	return strings.Join(quoted[:last], ", ") + " or " + quoted[last]
+}
+
+// archetypes checks that every element of the list at path names an archetype
+func archetypes(path string, names []string) error {
+	for i, name := range names {
+		if !isArchetype(name) {
+			return &FieldError{Path: index(path, i), Problem: fmt.Sprintf("must be %s, not %q", oneOfArchetypes(), name)}
+		}
+	}
+	return nil
+}
@@ -1,11 +1,21 @@
-const flock = [];
+// The simulation runs on the server, this sketch only renders the frames it streams.
 let frameBuffer = []
+let walls = []
+let flow = []
+let patches = []
+// Whether frames have been asked for that have not arrived yet, so that they are only asked for once
+let framesRequested = false
 
-const WIDTH = 1200;
-const HEIGHT = 600;
-const SLIDER_ROW = HEIGHT - 40;
-const SLIDER_ROW_MARGIN = 40;
-const SLIDER_SEP = 40;
+const WIDTH = 800;
+const HEIGHT = 400;
+const BUFFER_LOW_WATER = 60;
+const ARCHETYPE_COLOURS = {
+    agent: [255, 255, 255],
+    lover: [255, 105, 180],
+    ruler: [255, 215, 0],
+    predator: [255, 60, 60],
+    prey: [120, 200, 255],
+};
 
 
 const setup = (s) => () => {
@@ -14,9 +24,7 @@ const setup = (s) => () => {
 };
 
 
-let i = 0;
 const draw = (s) => () => {
-    s.background(0);
     show(s);
 };
 
@@ -28,21 +36,63 @@ let sketch = (s) => {
 const show = (s) => {
     if (frameBuffer.length < 1) {
         return
+    } else if (frameBuffer.length < BUFFER_LOW_WATER && !framesRequested) {
+        // Ask for another second of animation before the buffer runs dry
+        ws.send(60);
+        framesRequested = true;
     }
-    let position;
     s.background(0)
     let frame = frameBuffer.shift();
-    if (frame.includes("{")) {
-        position = JSON.parse(frame);
-    } else {
-        return;
+
+    // The flow field is drawn as short lines along the flow
+    s.strokeWeight(1);
+    s.stroke(0, 120, 120);
+    for (let sample of flow) {
+        s.line(sample.x, sample.y, sample.x + 2 * sample.u, sample.y + 2 * sample.v);
     }
 
+    // Resource patches are brighter the fuller they are, and repeated either side of each seam
+    s.noStroke();
+    for (let patch of patches) {
+        s.fill(0, 160, 0, 40 + 160 * patch.amount / patch.capacity);
+        for (let dx of [-WIDTH, 0, WIDTH]) {
+            for (let dy of [-HEIGHT, 0, HEIGHT]) {
+                s.circle(patch.x + dx, patch.y + dy, 2 * patch.radius);
+            }
+        }
+    }
+
+    // Walls are repeated either side of each seam so that walls crossing an edge are drawn whole
+    s.strokeWeight(2);
+    s.stroke(100, 100, 255);
+    for (let wall of walls) {
+        for (let dx of [-WIDTH, 0, WIDTH]) {
+            for (let dy of [-HEIGHT, 0, HEIGHT]) {
+                s.line(wall.x1 + dx, wall.y1 + dy, wall.x2 + dx, wall.y2 + dy);
+            }
+        }
+    }
+
+    s.strokeWeight(1);
+    s.noFill();
+    s.stroke(...ARCHETYPE_COLOURS.ruler);
+    for (let position of frame) {
+        if (!position.realm) {
+            continue;
+        }
+        for (let dx of [-WIDTH, 0, WIDTH]) {
+            for (let dy of [-HEIGHT, 0, HEIGHT]) {
+                s.circle(position.realm.x + dx, position.realm.y + dy, 2 * position.realm.radius);
+            }
+        }
+    }
 
     s.strokeWeight(8);
-    s.stroke(255);
-    s.point(position.x, position.y);
+    for (let position of frame) {
+        s.stroke(...(ARCHETYPE_COLOURS[position.archetype] || ARCHETYPE_COLOURS.agent));
+        s.point(position.x, position.y);
+    }
 }
 
 
-const sketchInstance = new p5(sketch);
\ No newline at end of file
+const sketchInstance = new p5(sketch);
@@ -2,22 +2,81 @@ package utils
 
 import (
 	"math"
-	"math/rand"
 	"time"
 )
 
-var random = rand.New(rand.NewSource(time.Now().UnixNano()))
+var random = NewRandom(time.Now().UnixNano())
 
 func RandFloat(a, b float64) float64 {
-	lower := math.Min(a, b)
-	return random.Float64()*math.Abs(a-b) + lower
+	return random.Float(a, b)
 }
 
 func RandInt(a, b int) int {
+	return random.Int(a, b)
+}
+
+// Random is a splitmix64 pseudo-random number generator. Its entire state is a single word, so it
+// is cheap to give every consumer its own, and copying a Random copies its future sequence. It is
+// not safe for concurrent use.
+type Random struct {
+	State uint64
+}
+
+// NewRandom returns a Random seeded with seed
+func NewRandom(seed int64) *Random {
+	return &Random{State: uint64(seed)}
+}
+
+// Uint64 returns the next pseudo-random 64 bit word
+func (r *Random) Uint64() uint64 {
+	r.State += 0x9e3779b97f4a7c15
+	z := r.State
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Float64 returns a pseudo-random number in [0, 1)
+func (r *Random) Float64() float64 {
+	return float64(r.Uint64()>>11) / (1 << 53)
+}
+
+// Float returns a pseudo-random number between a and b
+func (r *Random) Float(a, b float64) float64 {
+	lower := math.Min(a, b)
+	return r.Float64()*math.Abs(a-b) + lower
+}
+
+// Int returns a pseudo-random integer between a and b inclusive
+func (r *Random) Int(a, b int) int {
 	min, max := a, b
 	if b < a {
 		min, max = b, a
 	}
-	diff := max - min + 1
-	return random.Intn(diff) + min
+	diff := uint64(max - min + 1)
+	// Reject the incomplete final block of words so that every result is equally likely
+	limit := math.MaxUint64 - math.MaxUint64%diff
+	word := r.Uint64()
+	for word >= limit {
+		word = r.Uint64()
+	}
+	return int(word%diff) + min
+}
+
+// Normal returns a pseudo-random number from the normal distribution with the given mean and
+// standard deviation, by the Box-Muller transform
+func (r *Random) Normal(mean, deviation float64) float64 {
+	// 1 - Float64 is in (0, 1], so the logarithm is finite
+	radius := math.Sqrt(-2 * math.Log(1-r.Float64()))
+	return mean + deviation*radius*math.Cos(2*math.Pi*r.Float64())
+}
+
+// Split returns a new Random seeded from this one, so that it produces an independent sequence
+func (r *Random) Split() *Random {
+	return &Random{State: r.Uint64()}
+}
+
+// Copy returns a Random that will produce the same sequence as this one
+func (r *Random) Copy() *Random {
+	return &Random{State: r.State}
 }
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRandom_IsReproducible(t *testing.T) {
+	a, b := NewRandom(42), NewRandom(42)
+	for range [100]any{} {
+		assert.Equal(t, a.Uint64(), b.Uint64())
+	}
+
+	copied := a.Copy()
+	for range [100]any{} {
+		assert.Equal(t, a.Float64(), copied.Float64())
+	}
+}
+
+func TestRandom_Split_IsIndependent(t *testing.T) {
+	parent := NewRandom(42)
+	child := parent.Split()
+
+	assert.NotEqual(t, parent.Uint64(), child.Uint64())
+}
+
+func TestRandom_Ranges(t *testing.T) {
+	random := NewRandom(7)
+	seen := make(map[int]bool)
+	for range [1000]any{} {
+		f := random.Float(3, -2)
+		assert.GreaterOrEqual(t, f, -2.0)
+		assert.Less(t, f, 3.0)
+
+		i := random.Int(5, 1)
+		assert.GreaterOrEqual(t, i, 1)
+		assert.LessOrEqual(t, i, 5)
+		seen[i] = true
+	}
+	assert.Len(t, seen, 5)
+}
+
+func TestRandom_Normal_Moments(t *testing.T) {
+	random := NewRandom(11)
+	samples := 100000
+	sum, squares := 0.0, 0.0
+	for range make([]any, samples) {
+		x := random.Normal(5, 2)
+		sum += x
+		squares += x * x
+	}
+	mean := sum / float64(samples)
+	assert.InDelta(t, 5, mean, 0.05)
+	assert.InDelta(t, 4, squares/float64(samples)-mean*mean, 0.1)
+}
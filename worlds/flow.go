@@ -0,0 +1,83 @@
+package worlds
+
+import (
+	"math"
+	"tjweldon/archetypal-agents/utils"
+)
+
+// Mode is a single travelling Fourier mode of the stream function of a FlowField. The wave numbers
+// are whole numbers of wavelengths across the width and height, which makes the mode periodic.
+type Mode struct {
+	KX, KY    int
+	Amplitude float64
+	Frequency float64 // Angular frequency in radians per second
+	Phase     float64
+}
+
+// FlowField is a time-varying velocity field over a Width x Height torus. It is defined by the
+// stream function
+//
+//	ψ(x, y, t) = Σ Amplitude * sin(2π(KX x/Width + KY y/Height) + Frequency t + Phase)
+//
+// with velocity (∂ψ/∂y, -∂ψ/∂x). The curl of a stream function is divergence free, so the flow
+// has eddies but no sources or sinks, and since every mode is periodic it tiles seamlessly at the
+// wrap boundaries.
+type FlowField struct {
+	Width, Height float64
+	Modes         []Mode
+}
+
+// NewTurbulence initialises a FlowField with the given number of random modes. The wave numbers
+// are small so that eddies span a sizeable fraction of the world, and the amplitudes are scaled
+// so that the typical speed of the flow is strength. The modes are drawn from random.
+func NewTurbulence(width, height float64, modes int, strength float64, random *utils.Random) *FlowField {
+	field := &FlowField{Width: width, Height: height, Modes: make([]Mode, modes)}
+	for index := range field.Modes {
+		kx, ky := random.Int(-3, 3), random.Int(-3, 3)
+		if kx == 0 && ky == 0 {
+			kx = 1
+		}
+		wavenumber := 2 * math.Pi * math.Hypot(float64(kx)/width, float64(ky)/height)
+		field.Modes[index] = Mode{
+			KX:        kx,
+			KY:        ky,
+			Amplitude: strength / (wavenumber * math.Sqrt(float64(modes))),
+			Frequency: random.Float(-0.5, 0.5),
+			Phase:     random.Float(0, 2*math.Pi),
+		}
+	}
+	return field
+}
+
+// Velocity returns the velocity of the flow at (x, y) at time t (in seconds)
+func (f *FlowField) Velocity(x, y, t float64) (u, v float64) {
+	for _, mode := range f.Modes {
+		kx, ky := 2*math.Pi*float64(mode.KX)/f.Width, 2*math.Pi*float64(mode.KY)/f.Height
+		gradient := mode.Amplitude * math.Cos(kx*x+ky*y+mode.Frequency*t+mode.Phase)
+		u += gradient * ky
+		v -= gradient * kx
+	}
+	return u, v
+}
+
+// FlowSample is the velocity (U, V) of the flow at the point (X, Y)
+type FlowSample struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	U float64 `json:"u"`
+	V float64 `json:"v"`
+}
+
+// Sample evaluates the flow at time t at the centres of a columns x rows grid covering the torus
+func (f *FlowField) Sample(columns, rows int, t float64) []FlowSample {
+	samples := make([]FlowSample, 0, columns*rows)
+	for column := 0; column < columns; column++ {
+		for row := 0; row < rows; row++ {
+			x := (float64(column) + 0.5) * f.Width / float64(columns)
+			y := (float64(row) + 0.5) * f.Height / float64(rows)
+			u, v := f.Velocity(x, y, t)
+			samples = append(samples, FlowSample{X: x, Y: y, U: u, V: v})
+		}
+	}
+	return samples
+}
@@ -0,0 +1,46 @@
+package worlds
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"tjweldon/archetypal-agents/utils"
+)
+
+func TestFlowField_TilesSeamlessly(t *testing.T) {
+	field := NewTurbulence(800, 400, 8, 10, utils.NewRandom(1))
+
+	for range [100]any{} {
+		x, y, time := utils.RandFloat(0, 800), utils.RandFloat(0, 400), utils.RandFloat(0, 100)
+		u, v := field.Velocity(x, y, time)
+		for _, image := range [][2]float64{{x + 800, y}, {x, y - 400}, {x - 1600, y + 1200}} {
+			imageU, imageV := field.Velocity(image[0], image[1], time)
+			assert.InDelta(t, u, imageU, 1e-6)
+			assert.InDelta(t, v, imageV, 1e-6)
+		}
+	}
+}
+
+func TestFlowField_IsDivergenceFree(t *testing.T) {
+	field := NewTurbulence(800, 400, 8, 10, utils.NewRandom(1))
+	h := 1e-4
+
+	for range [100]any{} {
+		x, y, time := utils.RandFloat(0, 800), utils.RandFloat(0, 400), utils.RandFloat(0, 100)
+		right, _ := field.Velocity(x+h, y, time)
+		left, _ := field.Velocity(x-h, y, time)
+		_, up := field.Velocity(x, y+h, time)
+		_, down := field.Velocity(x, y-h, time)
+
+		assert.InDelta(t, 0.0, (right-left+up-down)/(2*h), 1e-6)
+	}
+}
+
+func TestFlowField_Sample(t *testing.T) {
+	field := NewTurbulence(800, 400, 4, 10, utils.NewRandom(1))
+
+	samples := field.Sample(8, 4, 3)
+
+	assert.Len(t, samples, 32)
+	u, v := field.Velocity(samples[5].X, samples[5].Y, 3)
+	assert.Equal(t, FlowSample{X: samples[5].X, Y: samples[5].Y, U: u, V: v}, samples[5])
+}
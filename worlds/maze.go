@@ -0,0 +1,149 @@
+package worlds
+
+import (
+	"tjweldon/archetypal-agents/domain/world"
+	"tjweldon/archetypal-agents/utils"
+)
+
+// Wall is an impassable straight line segment from (X1, Y1) to (X2, Y2). Walls must be shorter
+// than half the circumference of a periodic position space.
+type Wall struct {
+	X1 float64 `json:"x1"`
+	Y1 float64 `json:"y1"`
+	X2 float64 `json:"x2"`
+	Y2 float64 `json:"y2"`
+}
+
+// Cell is an impassable axis-aligned rectangle with its top left corner at (X, Y). Like walls,
+// cells must be smaller than half the circumference of a periodic position space. Agents are kept
+// out of a cell by its edges, so an agent placed inside one cannot leave it.
+type Cell struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// Walls returns the four edges of the Cell
+func (c Cell) Walls() []Wall {
+	left, top, right, bottom := c.X, c.Y, c.X+c.Width, c.Y+c.Height
+	return []Wall{
+		{left, top, right, top},
+		{right, top, right, bottom},
+		{right, bottom, left, bottom},
+		{left, bottom, left, top},
+	}
+}
+
+// Maze is a layer of walls and cells attached to a position space
+type Maze struct {
+	Space *world.MetricSpace2D
+	Walls []Wall
+	Cells []Cell
+}
+
+// NewMaze initialises a Maze over the position space with the given walls
+func NewMaze(space *world.MetricSpace2D, walls ...Wall) *Maze {
+	return &Maze{Space: space, Walls: walls}
+}
+
+// Barriers returns the walls of the Maze followed by the edges of each of its cells
+func (m *Maze) Barriers() []Wall {
+	barriers := append(make([]Wall, 0, len(m.Walls)+4*len(m.Cells)), m.Walls...)
+	for _, cell := range m.Cells {
+		barriers = append(barriers, cell.Walls()...)
+	}
+	return barriers
+}
+
+// Crossing finds the first wall or edge of a cell crossed by a path that starts at origin and is
+// displaced by (deltaX, deltaY). It returns the fraction of the path travelled before the
+// crossing, the wall that was crossed, and whether any wall is crossed at all.
+func (m *Maze) Crossing(origin *world.Vector, deltaX, deltaY float64) (fraction float64, wall Wall, crosses bool) {
+	fraction = 1
+	for _, candidate := range m.Barriers() {
+		a, b := m.Space.NewVector(candidate.X1, candidate.Y1), m.Space.NewVector(candidate.X2, candidate.Y2)
+		if at, ok := m.Space.Crossing(origin, deltaX, deltaY, a, b); ok && at <= fraction {
+			fraction, wall, crosses = at, candidate, true
+		}
+	}
+	return fraction, wall, crosses
+}
+
+// Normal returns a unit normal of the wall in the position space, the sign is arbitrary
+func (m *Maze) Normal(wall Wall) (x, y float64) {
+	deltaX, deltaY := m.Space.GeodesicDiff(wall.X1, wall.X2, wall.Y1, wall.Y2)
+	length := m.Space.Metric(m.Space.NewVector(wall.X1, wall.Y1), m.Space.NewVector(wall.X2, wall.Y2))
+	return -deltaY / length, deltaX / length
+}
+
+// GenerateMaze carves a perfect maze through a periodic grid of columns x rows cells covering a
+// width x height torus. Passages wrap around the seams, so the maze has no outer boundary. The
+// result is the walls that remain between cells. Walls are the length of a cell edge, so there
+// must be at least three columns and rows for walls to be shorter than half the circumference.
+// The passages are chosen using random, so the same seed carves the same maze.
+func GenerateMaze(width, height float64, columns, rows int, random *utils.Random) []Wall {
+	cellWidth, cellHeight := width/float64(columns), height/float64(rows)
+
+	// Every cell owns the walls on its right and bottom edges, neighbours own the rest
+	right, bottom := make([][]bool, columns), make([][]bool, columns)
+	visited := make([][]bool, columns)
+	for column := range right {
+		right[column], bottom[column], visited[column] = make([]bool, rows), make([]bool, rows), make([]bool, rows)
+		for row := range right[column] {
+			right[column][row], bottom[column][row] = true, true
+		}
+	}
+
+	// Recursive backtracker, using an explicit stack of cells
+	type cell struct{ column, row int }
+	stack := []cell{{0, 0}}
+	visited[0][0] = true
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		wrap := func(column, row int) cell {
+			return cell{(column + columns) % columns, (row + rows) % rows}
+		}
+		candidates := make([]cell, 0, 4)
+		for _, next := range []cell{
+			wrap(current.column+1, current.row), wrap(current.column-1, current.row),
+			wrap(current.column, current.row+1), wrap(current.column, current.row-1),
+		} {
+			if !visited[next.column][next.row] {
+				candidates = append(candidates, next)
+			}
+		}
+		if len(candidates) == 0 {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		next := candidates[random.Int(0, len(candidates)-1)]
+		switch {
+		case next == wrap(current.column+1, current.row):
+			right[current.column][current.row] = false
+		case next == wrap(current.column-1, current.row):
+			right[next.column][next.row] = false
+		case next == wrap(current.column, current.row+1):
+			bottom[current.column][current.row] = false
+		default:
+			bottom[next.column][next.row] = false
+		}
+		visited[next.column][next.row] = true
+		stack = append(stack, next)
+	}
+
+	walls := make([]Wall, 0)
+	for column := 0; column < columns; column++ {
+		for row := 0; row < rows; row++ {
+			left, top := float64(column)*cellWidth, float64(row)*cellHeight
+			if right[column][row] {
+				walls = append(walls, Wall{left + cellWidth, top, left + cellWidth, top + cellHeight})
+			}
+			if bottom[column][row] {
+				walls = append(walls, Wall{left, top + cellHeight, left + cellWidth, top + cellHeight})
+			}
+		}
+	}
+	return walls
+}
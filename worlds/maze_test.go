@@ -0,0 +1,71 @@
+package worlds
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"tjweldon/archetypal-agents/domain/world"
+	"tjweldon/archetypal-agents/utils"
+)
+
+const MaxPrecision = 1.0e-9
+
+func TestGenerateMaze_IsPerfect(t *testing.T) {
+	columns, rows := 8, 4
+
+	walls := GenerateMaze(800, 400, columns, rows, utils.NewRandom(1))
+
+	// A spanning tree of the cells removes one wall for every cell but the first
+	assert.Len(t, walls, 2*columns*rows-(columns*rows-1))
+}
+
+func TestCell_Walls_AreClosed(t *testing.T) {
+	walls := Cell{X: 10, Y: 20, Width: 30, Height: 40}.Walls()
+
+	assert.Len(t, walls, 4)
+	for index, wall := range walls {
+		next := walls[(index+1)%len(walls)]
+		assert.Equal(t, wall.X2, next.X1)
+		assert.Equal(t, wall.Y2, next.Y1)
+	}
+}
+
+func TestMaze_Crossing_FindsFirstWall(t *testing.T) {
+	maze := NewMaze(world.NewEuclideanToroid(100, 100), Cell{X: 10, Y: 10, Width: 10, Height: 10}.Walls()...)
+
+	fraction, wall, crosses := maze.Crossing(maze.Space.NewVector(0, 15), 40, 0)
+
+	assert.True(t, crosses)
+	assert.InDelta(t, 0.25, fraction, MaxPrecision)
+	assert.Equal(t, Wall{10, 20, 10, 10}, wall)
+}
+
+func TestMaze_Crossing_Cells(t *testing.T) {
+	maze := NewMaze(world.NewEuclideanToroid(100, 100), Wall{50, 0, 50, 30})
+	maze.Cells = []Cell{{X: 95, Y: 10, Width: 10, Height: 10}}
+
+	fraction, wall, crosses := maze.Crossing(maze.Space.NewVector(85, 15), 20, 0)
+
+	assert.True(t, crosses, "the cell straddles the seam")
+	assert.InDelta(t, 0.5, fraction, MaxPrecision)
+	assert.Equal(t, Wall{95, 20, 95, 10}, wall)
+	assert.Len(t, maze.Barriers(), 5)
+}
+
+func TestMaze_Crossing_AcrossSeam(t *testing.T) {
+	maze := NewMaze(world.NewEuclideanToroid(100, 100), Wall{0, 40, 0, 60})
+
+	_, _, crosses := maze.Crossing(maze.Space.NewVector(95, 50), 10, 0)
+
+	assert.True(t, crosses)
+}
+
+func TestMaze_Normal_IsUnitAndPerpendicular(t *testing.T) {
+	maze := NewMaze(world.NewEuclideanToroid(100, 100))
+	wall := Wall{10, 10, 13, 14}
+
+	x, y := maze.Normal(wall)
+
+	assert.InDelta(t, 1.0, math.Hypot(x, y), MaxPrecision)
+	assert.InDelta(t, 0.0, x*3+y*4, MaxPrecision)
+}
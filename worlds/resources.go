@@ -0,0 +1,77 @@
+package worlds
+
+import (
+	"math"
+	"tjweldon/archetypal-agents/domain/world"
+	"tjweldon/archetypal-agents/utils"
+)
+
+// Patch is a disc of Radius about (X, Y) holding an Amount of resource, which regrows at Regrowth
+// per second up to Capacity
+type Patch struct {
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	Radius   float64 `json:"radius"`
+	Amount   float64 `json:"amount"`
+	Capacity float64 `json:"capacity"`
+	Regrowth float64 `json:"regrowth"`
+}
+
+// Resources is a layer of resource patches attached to a position space
+type Resources struct {
+	Space   *world.MetricSpace2D
+	Patches []Patch
+}
+
+// NewResources initialises Resources over the position space with the given patches
+func NewResources(space *world.MetricSpace2D, patches ...Patch) *Resources {
+	return &Resources{Space: space, Patches: patches}
+}
+
+// ScatterPatches places count full patches of the given radius, capacity and regrowth uniformly at
+// random over a width x height world. The positions are drawn from random.
+func ScatterPatches(width, height float64, count int, radius, capacity, regrowth float64, random *utils.Random) []Patch {
+	patches := make([]Patch, count)
+	for index := range patches {
+		patches[index] = Patch{
+			X:        random.Float(0, width),
+			Y:        random.Float(0, height),
+			Radius:   radius,
+			Amount:   capacity,
+			Capacity: capacity,
+			Regrowth: regrowth,
+		}
+	}
+	return patches
+}
+
+// Regrow regrows every patch for dt seconds
+func (r *Resources) Regrow(dt float64) {
+	for index := range r.Patches {
+		patch := &r.Patches[index]
+		patch.Amount = math.Min(patch.Capacity, patch.Amount+patch.Regrowth*dt)
+	}
+}
+
+// Consume takes up to demand from the patches that contain position, in order, and returns the
+// amount taken
+func (r *Resources) Consume(position *world.Vector, demand float64) (consumed float64) {
+	for index := range r.Patches {
+		patch := &r.Patches[index]
+		if consumed >= demand || r.Space.Metric(r.Space.NewVector(patch.X, patch.Y), position) >= patch.Radius {
+			continue
+		}
+		taken := math.Min(patch.Amount, demand-consumed)
+		patch.Amount -= taken
+		consumed += taken
+	}
+	return consumed
+}
+
+// Total returns the amount of resource over all the patches
+func (r *Resources) Total() (total float64) {
+	for _, patch := range r.Patches {
+		total += patch.Amount
+	}
+	return total
+}
@@ -0,0 +1,48 @@
+package worlds
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"tjweldon/archetypal-agents/domain/world"
+	"tjweldon/archetypal-agents/utils"
+)
+
+func TestResources_ConsumeAcrossSeam(t *testing.T) {
+	space := world.NewEuclideanToroid(800, 400)
+	resources := NewResources(space, Patch{X: 795, Y: 200, Radius: 10, Amount: 5, Capacity: 10})
+
+	assert.Equal(t, 0.0, resources.Consume(space.NewVector(20, 200), 3), "out of reach")
+	assert.Equal(t, 3.0, resources.Consume(space.NewVector(2, 200), 3))
+	assert.Equal(t, 2.0, resources.Consume(space.NewVector(2, 200), 3), "only what is left")
+	assert.Equal(t, 0.0, resources.Total())
+}
+
+func TestResources_ConsumeFromOverlappingPatches(t *testing.T) {
+	space := world.NewEuclideanToroid(800, 400)
+	resources := NewResources(space,
+		Patch{X: 100, Y: 100, Radius: 10, Amount: 1},
+		Patch{X: 105, Y: 100, Radius: 10, Amount: 4},
+	)
+
+	assert.Equal(t, 3.0, resources.Consume(space.NewVector(102, 100), 3))
+	assert.Equal(t, 2.0, resources.Total())
+}
+
+func TestResources_RegrowsToCapacity(t *testing.T) {
+	resources := NewResources(world.NewEuclideanToroid(800, 400), Patch{Amount: 1, Capacity: 4, Regrowth: 2})
+
+	resources.Regrow(1)
+	assert.Equal(t, 3.0, resources.Patches[0].Amount)
+	resources.Regrow(1)
+	assert.Equal(t, 4.0, resources.Patches[0].Amount)
+}
+
+func TestScatterPatches_FillsTheWorld(t *testing.T) {
+	patches := ScatterPatches(800, 400, 20, 15, 10, 1, utils.NewRandom(1))
+
+	assert.Len(t, patches, 20)
+	for _, patch := range patches {
+		assert.True(t, patch.X >= 0 && patch.X < 800 && patch.Y >= 0 && patch.Y < 400)
+		assert.Equal(t, patch.Capacity, patch.Amount)
+	}
+}
@@ -1 +1,3 @@
+// Package worlds contains the environments that agents are placed in. An environment is static or
+// slowly varying structure layered over the position space, such as walls.
 package worlds